@@ -6,6 +6,7 @@ import (
 )
 
 const sessionCookieName = "SF_SESSION_ID"
+const guestCookieName = "SF_GUEST_TOKEN"
 
 func GetValidSessionFromCookie(c *gin.Context) *model.LoginSession {
 	sessionId := GetSessionIdFromCookie(c)
@@ -39,3 +40,25 @@ func SetCookie(c *gin.Context, sessionId string) {
 func RemoveCookie(c *gin.Context) {
 	c.SetCookie(sessionCookieName, "", -1, "/", "", false, true)
 }
+
+// Guest cookies are scoped to the session view, so guests can have different identities in different sessions
+func GetGuestTokenFromCookie(c *gin.Context) *string {
+	guestToken, err := c.Cookie(guestCookieName)
+	if err != nil || guestToken == "" {
+		return nil
+	}
+
+	return &guestToken
+}
+
+func SetGuestCookie(c *gin.Context, joinId string, guestToken string) {
+	c.SetCookie(guestCookieName, guestToken, 0, guestCookiePath(joinId), "", false, true)
+}
+
+func RemoveGuestCookie(c *gin.Context, joinId string) {
+	c.SetCookie(guestCookieName, "", -1, guestCookiePath(joinId), "", false, true)
+}
+
+func guestCookiePath(joinId string) string {
+	return "/session/view/" + joinId
+}
@@ -0,0 +1,53 @@
+package authentication
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/partyoffice/spotifete/config"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+// Guest tokens have the form <guest id>.<signature> and are only valid for the session the guest joined
+func NewGuestToken(guestId uint, sessionId uint) string {
+	return fmt.Sprintf("%d.%s", guestId, signGuest(guestId, sessionId))
+}
+
+func GuestIdFromToken(guestToken string, sessionId uint) (uint, *SpotifeteError) {
+	tokenParts := strings.SplitN(guestToken, ".", 2)
+	if len(tokenParts) != 2 {
+		return 0, NewUserError("Invalid guest token.")
+	}
+
+	guestId, err := strconv.ParseUint(tokenParts[0], 10, 0)
+	if err != nil {
+		return 0, NewUserError("Invalid guest token.")
+	}
+
+	expectedSignature := signGuest(uint(guestId), sessionId)
+	if !hmac.Equal([]byte(tokenParts[1]), []byte(expectedSignature)) {
+		return 0, NewUserError("Invalid guest token.")
+	}
+
+	return uint(guestId), nil
+}
+
+func signGuest(guestId uint, sessionId uint) string {
+	mac := hmac.New(sha256.New, guestTokenSecret())
+	mac.Write([]byte(fmt.Sprintf("%d:%d", guestId, sessionId)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func guestTokenSecret() []byte {
+	c := config.Get()
+	if c.SpotifeteConfiguration.GuestTokenSecret != nil {
+		return []byte(*c.SpotifeteConfiguration.GuestTokenSecret)
+	}
+
+	// Fall back to another secret that is only known to this instance
+	return []byte(c.SpotifyConfiguration.Secret)
+}
@@ -1,6 +1,7 @@
 package authentication
 
 import (
+	"context"
 	"net/http"
 	"sync"
 
@@ -8,12 +9,31 @@ import (
 	"github.com/partyoffice/spotifete/config"
 	"github.com/partyoffice/spotifete/database/model"
 	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
 	"github.com/zmb3/spotify"
 	"golang.org/x/oauth2"
 )
 
-func NewClientForToken(token *oauth2.Token) spotify.Client {
-	return getSpotifyAuthenticator().NewClient(token)
+var newClientForToken = func(token *oauth2.Token) spotifyApi.Client {
+	httpClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: getOauthConfig().TokenSource(context.Background(), token),
+			Base:   spotifyApi.NewTransport(http.DefaultTransport),
+		},
+	}
+
+	client := spotify.NewClient(httpClient)
+	return &client
+}
+
+// Every client has its own rate limit for requests to Spotify, so there should only be one per user
+func NewClientForToken(token *oauth2.Token) spotifyApi.Client {
+	return newClientForToken(token)
+}
+
+// Replaces how clients are created, e.g. to talk to the fake Spotify API instead. Has to be called before any client is created.
+func SetClientFactory(factory func(token *oauth2.Token) spotifyApi.Client) {
+	newClientForToken = factory
 }
 
 func GetTokenFromCallback(callbackContext *gin.Context) (*oauth2.Token, *SpotifeteError) {
@@ -40,9 +60,26 @@ func createAuthenticator() {
 	c := config.Get()
 	callbackUrl := c.SpotifeteConfiguration.BaseUrl + "/auth/callback"
 
-	authenticator = spotify.NewAuthenticator(callbackUrl, spotify.ScopePlaylistReadPrivate, spotify.ScopePlaylistModifyPrivate, spotify.ScopeImageUpload, spotify.ScopeUserLibraryRead, spotify.ScopeUserModifyPlaybackState, spotify.ScopeUserReadCurrentlyPlaying, spotify.ScopeUserReadPrivate)
+	authenticator = spotify.NewAuthenticator(callbackUrl, spotify.ScopePlaylistReadPrivate, spotify.ScopePlaylistModifyPrivate, spotify.ScopeImageUpload, spotify.ScopeUserLibraryRead, spotify.ScopeUserModifyPlaybackState, spotify.ScopeUserReadPlaybackState, spotify.ScopeUserReadCurrentlyPlaying, spotify.ScopeUserReadPrivate)
 	authenticator.SetAuthInfo(c.SpotifyConfiguration.Id, c.SpotifyConfiguration.Secret)
+
+	// The authenticator does not let us choose the transport of its clients, so they are built from this config instead
+	oauthConfig = &oauth2.Config{
+		ClientID:     c.SpotifyConfiguration.Id,
+		ClientSecret: c.SpotifyConfiguration.Secret,
+		RedirectURL:  callbackUrl,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  spotify.AuthURL,
+			TokenURL: spotify.TokenURL,
+		},
+	}
+}
+
+func getOauthConfig() *oauth2.Config {
+	createAuthenticatorOne.Do(createAuthenticator)
+	return oauthConfig
 }
 
 var createAuthenticatorOne sync.Once
 var authenticator spotify.Authenticator
+var oauthConfig *oauth2.Config
@@ -3,7 +3,9 @@ package config
 import (
 	"github.com/google/logger"
 	"github.com/spf13/viper"
+	"os"
 	"sync"
+	"time"
 )
 
 type Configuration struct {
@@ -42,6 +44,10 @@ func createViperConfiguration() *viper.Viper {
 	viperConfiguration := viper.New()
 	viperConfiguration.SetConfigType("yaml")
 	viperConfiguration.SetConfigName("spotifete-config")
+	// Takes precedence, e.g. to run the integration tests against a test database
+	if configDirectory := os.Getenv("SPOTIFETE_CONFIG_DIRECTORY"); configDirectory != "" {
+		viperConfiguration.AddConfigPath(configDirectory)
+	}
 	viperConfiguration.AddConfigPath("/etc/spotifete")
 	viperConfiguration.AddConfigPath(".")
 	return viperConfiguration
@@ -92,6 +98,21 @@ func getOptionalInt(viperConfiguration *viper.Viper, key string) *int {
 	}
 }
 
+// Durations are written like 5s or 1h30m
+func getDurationOrDefault(viperConfiguration *viper.Viper, key string, defaultValue time.Duration) time.Duration {
+	if !viperConfiguration.IsSet(key) {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(viperConfiguration.GetString(key))
+	if err != nil || duration <= 0 {
+		logger.Warningf("Duration configuration parameter %s is invalid, falling back to default %s.", key, defaultValue)
+		return defaultValue
+	}
+
+	return duration
+}
+
 func getBool(viperConfiguration *viper.Viper, key string) bool {
 	if viperConfiguration.IsSet(key) {
 		return viperConfiguration.GetBool(key)
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type pollingConfiguration struct {
+	// Sessions without a playing track are polled this often, e.g. to notice that the owner started playback
+	Interval time.Duration
+	// While a track is playing, sessions are polled shortly after it ends, but never less often than this.
+	// Skips in the Spotify app are only noticed with the next poll, so this should stay well below the length of a track.
+	MaxInterval time.Duration
+	// Sessions that were not updated for this long are idle
+	IdleThreshold time.Duration
+	IdleInterval  time.Duration
+	// How many sessions are polled at the same time
+	MaxWorkers int
+}
+
+func (c pollingConfiguration) read(viperConfiguration *viper.Viper) pollingConfiguration {
+	c.Interval = getDurationOrDefault(viperConfiguration, "spotifete.polling.interval", 5*time.Second)
+	c.MaxInterval = getDurationOrDefault(viperConfiguration, "spotifete.polling.maxInterval", 10*time.Second)
+	c.IdleThreshold = getDurationOrDefault(viperConfiguration, "spotifete.polling.idleThreshold", time.Hour)
+	c.IdleInterval = getDurationOrDefault(viperConfiguration, "spotifete.polling.idleInterval", 5*time.Minute)
+
+	configuredMaxWorkers := getOptionalInt(viperConfiguration, "spotifete.polling.maxWorkers")
+	if configuredMaxWorkers == nil || *configuredMaxWorkers < 1 {
+		c.MaxWorkers = 10
+	} else {
+		c.MaxWorkers = *configuredMaxWorkers
+	}
+
+	if c.MaxInterval < c.Interval {
+		c.MaxInterval = c.Interval
+	}
+
+	return c
+}
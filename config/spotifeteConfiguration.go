@@ -9,7 +9,11 @@ type spotifeteConfiguration struct {
 	Port             int
 	ReleaseMode      bool
 	LogDirectory     string
+	GuestTokenSecret *string
+	// Spotify IDs of the users that may see internals like cache statistics
+	AdminSpotifyIds  []string
 	AppConfiguration appConfiguration
+	Polling          pollingConfiguration
 }
 
 func (c spotifeteConfiguration) read(viperConfiguration *viper.Viper) spotifeteConfiguration {
@@ -29,7 +33,10 @@ func (c spotifeteConfiguration) read(viperConfiguration *viper.Viper) spotifeteC
 		c.LogDirectory = *logDirectory
 	}
 
+	c.GuestTokenSecret = getOptionalString(viperConfiguration, "spotifete.guestTokenSecret")
+	c.AdminSpotifyIds = viperConfiguration.GetStringSlice("spotifete.adminSpotifyIds")
 	c.AppConfiguration = appConfiguration{}.read(viperConfiguration)
+	c.Polling = pollingConfiguration{}.read(viperConfiguration)
 
 	return c
 }
@@ -0,0 +1,7 @@
+package model
+
+type Guest struct {
+	BaseModel
+	SessionId   uint   `json:"-"`
+	DisplayName string `json:"display_name"`
+}
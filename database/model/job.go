@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Jobs are deleted once they ran successfully
+type Job struct {
+	BaseModel
+	JobType     string
+	Payload     string
+	Status      string
+	Attempts    uint
+	MaxAttempts uint
+	RunAt       time.Time
+	LockedUntil *time.Time
+	LastError   *string
+}
+
+const (
+	JobStatusPending = "PENDING"
+	JobStatusRunning = "RUNNING"
+	JobStatusDead    = "DEAD"
+)
@@ -1,14 +1,43 @@
 package model
 
+import "time"
+
 type SimpleListeningSession struct {
 	BaseModel
-	Active                  bool    `json:"active"`
-	OwnerId                 uint    `json:"owner_id"`
-	JoinId                  string  `json:"join_id"`
-	QueuePlaylistId         string  `gorm:"column:queue_playlist" json:"queue_playlist_id"`
-	Title                   string  `json:"title"`
-	FallbackPlaylistId      *string `gorm:"column:fallback_playlist" json:"fallback_playlist_id"`
-	FallbackPlaylistShuffle bool    `json:"fallback_playlist_shuffle"`
+	Active                  bool       `json:"active"`
+	OwnerId                 uint       `json:"owner_id"`
+	JoinId                  string     `json:"join_id"`
+	QueuePlaylistId         string     `gorm:"column:queue_playlist" json:"queue_playlist_id"`
+	Title                   string     `json:"title"`
+	FallbackPlaylistId      *string    `gorm:"column:fallback_playlist" json:"fallback_playlist_id"`
+	FallbackPlaylistShuffle bool       `json:"fallback_playlist_shuffle"`
+	QueueStrategy           string     `json:"queue_strategy"`
+	PlaybackBackend         string     `json:"playback_backend"`
+	QueueLookahead          uint       `json:"queue_lookahead"`
+	Moderated               bool       `json:"moderated"`
+	PlaybackState           string     `json:"playback_state"`
+	PlaybackStateSince      *time.Time `json:"playback_state_since"`
+	AutoRepairPlayback      bool       `json:"auto_repair_playback"`
+	// Country code tracks have to be available in. Nil if the country of the owner is used.
+	Market *string `json:"market"`
+	// Created when the session is closed
+	RewindPlaylistId *string `gorm:"column:rewind_playlist" json:"rewind_playlist_id"`
+	RequestLimits
+	ContentFilters
+}
+
+// A limit of 0 means unlimited
+type RequestLimits struct {
+	MaxUnplayedRequests       uint `json:"max_unplayed_requests"`
+	MaxRequestsPerWindow      uint `json:"max_requests_per_window"`
+	RequestWindowMinutes      uint `json:"request_window_minutes"`
+	MinSecondsBetweenRequests uint `json:"min_seconds_between_requests"`
+}
+
+// A maximum duration of 0 means unlimited
+type ContentFilters struct {
+	AllowExplicit           bool `json:"allow_explicit"`
+	MaxTrackDurationSeconds uint `json:"max_track_duration_seconds"`
 }
 
 func (SimpleListeningSession) TableName() string {
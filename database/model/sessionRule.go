@@ -0,0 +1,19 @@
+package model
+
+type SessionRule struct {
+	BaseModel
+	SessionId uint   `json:"-"`
+	RuleType  string `json:"rule_type"`
+	Subject   string `json:"subject"`
+	SpotifyId string `json:"spotify_id"`
+	Name      string `json:"name"`
+}
+
+const (
+	SessionRuleTypeBlock = "BLOCK"
+	SessionRuleTypeAllow = "ALLOW"
+
+	SessionRuleSubjectTrack  = "TRACK"
+	SessionRuleSubjectArtist = "ARTIST"
+	SessionRuleSubjectAlbum  = "ALBUM"
+)
@@ -1,12 +1,28 @@
 package model
 
+import "time"
+
 type SongRequest struct {
 	BaseModel
-	SessionId      uint          `json:"session_id"`
-	SpotifyTrackId string        `json:"spotify_track_id"`
-	TrackMetadata  TrackMetadata `gorm:"foreignKey:spotify_track_id;references:spotify_track_id" json:"track_metadata"`
-	Played         bool          `json:"-"`
-	Locked         bool          `json:"-"`
-	RequestedBy    string        `json:"requested_by"`
-	Weight         int64         `json:"-"`
+	SessionId       uint          `json:"session_id"`
+	SpotifyTrackId  string        `json:"spotify_track_id"`
+	TrackMetadata   TrackMetadata `gorm:"foreignKey:spotify_track_id;references:spotify_track_id" json:"track_metadata"`
+	Played          bool          `json:"-"`
+	PlayedAt        *time.Time    `json:"played_at"`
+	FromFallback    bool          `json:"from_fallback"`
+	Locked          bool          `json:"-"`
+	RequestedBy     string        `json:"requested_by"`
+	GuestId         *uint         `json:"-"`
+	Weight          int64         `json:"-"`
+	VoteScore       int64         `json:"vote_score"`
+	ApprovalStatus  string        `json:"approval_status"`
+	RejectionReason *string       `json:"rejection_reason"`
+	ManualPosition  *int          `json:"-"`
+	PushedToPlayer  bool          `json:"-"`
 }
+
+const (
+	ApprovalStatusApproved = "APPROVED"
+	ApprovalStatusPending  = "PENDING"
+	ApprovalStatusRejected = "REJECTED"
+)
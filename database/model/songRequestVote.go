@@ -0,0 +1,8 @@
+package model
+
+type SongRequestVote struct {
+	BaseModel
+	SongRequestId uint  `json:"-"`
+	GuestId       uint  `json:"-"`
+	Value         int64 `json:"value"`
+}
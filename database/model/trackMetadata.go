@@ -1,9 +1,11 @@
 package model
 
 import (
+	"fmt"
 	"github.com/partyoffice/spotifete/shared"
 	"github.com/zmb3/spotify"
 	"strings"
+	"time"
 )
 
 type TrackMetadata struct {
@@ -13,6 +15,17 @@ type TrackMetadata struct {
 	ArtistName             string `json:"artist_name"`
 	AlbumName              string `json:"album_name"`
 	AlbumImageThumbnailUrl string `json:"album_image_thumbnail_url"`
+	DurationMs             int    `json:"duration_ms"`
+	Explicit               bool   `json:"explicit"`
+	Popularity             int    `json:"popularity"`
+	Isrc                   string `json:"isrc"`
+	// Comma separated, in the same order as the artist names
+	SpotifyArtistIds string `json:"spotify_artist_ids"`
+	SpotifyAlbumId   string `json:"spotify_album_id"`
+	PreviewUrl       string `json:"preview_url"`
+	// The market the track was fetched for the last time and whether it was playable there
+	CheckedMarket string `json:"-"`
+	Playable      bool   `json:"-"`
 }
 
 func (trackMetadata TrackMetadata) SetMetadata(spotifyTrack spotify.FullTrack) TrackMetadata {
@@ -21,12 +34,44 @@ func (trackMetadata TrackMetadata) SetMetadata(spotifyTrack spotify.FullTrack) T
 	trackMetadata.AlbumName = spotifyTrack.Album.Name
 
 	var artistNames []string
+	var artistIds []string
 	for _, artist := range spotifyTrack.Artists {
 		artistNames = append(artistNames, artist.Name)
+		artistIds = append(artistIds, artist.ID.String())
 	}
 
 	trackMetadata.ArtistName = strings.Join(artistNames, ", ")
 	trackMetadata.AlbumImageThumbnailUrl = shared.FindSmallestImageUrlOrEmpty(spotifyTrack.Album.Images)
+	trackMetadata.DurationMs = spotifyTrack.Duration
+	trackMetadata.Explicit = spotifyTrack.Explicit
+	trackMetadata.Popularity = spotifyTrack.Popularity
+	trackMetadata.Isrc = spotifyTrack.ExternalIDs["isrc"]
+	trackMetadata.SpotifyArtistIds = strings.Join(artistIds, ",")
+	trackMetadata.SpotifyAlbumId = spotifyTrack.Album.ID.String()
+	trackMetadata.PreviewUrl = spotifyTrack.PreviewURL
 
 	return trackMetadata
 }
+
+// Whether a track is playable depends on the market it was fetched for
+func (trackMetadata TrackMetadata) SetPlayability(market string, isPlayable *bool) TrackMetadata {
+	trackMetadata.CheckedMarket = market
+	trackMetadata.Playable = isPlayable != nil && *isPlayable
+
+	return trackMetadata
+}
+
+// Fresh metadata can be used instead of fetching the track from Spotify again, but only for the same market
+func (trackMetadata TrackMetadata) IsFreshFor(market string, maxAge time.Duration) bool {
+	return trackMetadata.CheckedMarket == market && time.Since(trackMetadata.UpdatedAt) < maxAge
+}
+
+func (trackMetadata TrackMetadata) Duration() time.Duration {
+	return time.Duration(trackMetadata.DurationMs) * time.Millisecond
+}
+
+// Formats the duration like Spotify does, e.g. 3:07
+func (trackMetadata TrackMetadata) FormattedDuration() string {
+	totalSeconds := trackMetadata.DurationMs / 1000
+	return fmt.Sprintf("%d:%02d", totalSeconds/60, totalSeconds%60)
+}
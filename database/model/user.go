@@ -1,19 +1,22 @@
 package model
 
 import (
+	"github.com/zmb3/spotify"
 	"golang.org/x/oauth2"
 	"time"
 )
 
 type SimpleUser struct {
 	BaseModel
-	SpotifyId           string    `json:"spotify_id"`
-	SpotifyDisplayName  string    `json:"spotify_display_name"`
-	Country             string    `json:"country"`
-	SpotifyAccessToken  string    `json:"-"`
-	SpotifyRefreshToken string    `json:"-"`
-	SpotifyTokenType    string    `json:"-"`
-	SpotifyTokenExpiry  time.Time `json:"-"`
+	SpotifyId           string     `json:"spotify_id"`
+	SpotifyDisplayName  string     `json:"spotify_display_name"`
+	Country             string     `json:"country"`
+	Product             string     `json:"product"`
+	ProfileRefreshedAt  *time.Time `json:"-"`
+	SpotifyAccessToken  string     `json:"-"`
+	SpotifyRefreshToken string     `json:"-"`
+	SpotifyTokenType    string     `json:"-"`
+	SpotifyTokenExpiry  time.Time  `json:"-"`
 }
 
 func (SimpleUser) TableName() string {
@@ -42,6 +45,17 @@ func (u SimpleUser) SetToken(token *oauth2.Token) SimpleUser {
 	return u
 }
 
+func (u SimpleUser) SetProfile(profile spotify.PrivateUser) SimpleUser {
+	now := time.Now()
+
+	u.SpotifyDisplayName = profile.DisplayName
+	u.Country = profile.Country
+	u.Product = profile.Product
+	u.ProfileRefreshedAt = &now
+
+	return u
+}
+
 type FullUser struct {
 	SimpleUser
 
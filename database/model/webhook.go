@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+type Webhook struct {
+	BaseModel
+	SessionId uint   `json:"-"`
+	Url       string `json:"url"`
+	Secret    string `json:"-"`
+	// Comma separated
+	Events string `json:"-"`
+}
+
+func (webhook Webhook) EventList() []string {
+	return strings.Split(webhook.Events, ",")
+}
+
+func (webhook Webhook) IsSubscribedTo(event string) bool {
+	for _, subscribedEvent := range webhook.EventList() {
+		if subscribedEvent == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookDelivery struct {
+	BaseModel
+	WebhookId      uint       `json:"-"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       uint       `json:"attempts"`
+	ResponseStatus *int       `json:"response_status"`
+	Error          *string    `json:"error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+const (
+	WebhookEventRequestCreated = "REQUEST_CREATED"
+	WebhookEventTrackStarted   = "TRACK_STARTED"
+	WebhookEventSessionClosed  = "SESSION_CLOSED"
+
+	WebhookDeliveryStatusPending   = "PENDING"
+	WebhookDeliveryStatusDelivered = "DELIVERED"
+	WebhookDeliveryStatusFailed    = "FAILED"
+)
@@ -0,0 +1,207 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/logger"
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	workerCount        = 2
+	pollInterval       = time.Second
+	defaultMaxAttempts = 8
+	// A job that is still running after this time is considered crashed and picked up by another worker
+	jobTimeout      = 5 * time.Minute
+	minRetryBackoff = 30 * time.Second
+	maxRetryBackoff = time.Hour
+)
+
+var handlers = struct {
+	sync.RWMutex
+	byType map[string]func(payload string) error
+}{
+	byType: map[string]func(payload string) error{},
+}
+
+// Registers the handler for all jobs of the given type. A handler is retried if it returns an error, so it should be idempotent.
+func Register[P any](jobType string, handler func(payload P) error) {
+
+	handlers.Lock()
+	defer handlers.Unlock()
+
+	handlers.byType[jobType] = func(rawPayload string) error {
+		var payload P
+		err := json.Unmarshal([]byte(rawPayload), &payload)
+		if err != nil {
+			return fmt.Errorf("could not deserialize payload: %w", err)
+		}
+
+		return handler(payload)
+	}
+}
+
+// The job only runs if the transaction is committed
+func EnqueueInTransaction(jobType string, payload any, tx *gorm.DB) error {
+
+	serializedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.Job{
+		JobType:     jobType,
+		Payload:     string(serializedPayload),
+		Status:      model.JobStatusPending,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       time.Now(),
+	}).Error
+}
+
+func StartWorkers() {
+	for i := 0; i < workerCount; i++ {
+		go work()
+	}
+}
+
+func work() {
+	for {
+		processed := processNextJob()
+		if !processed {
+			time.Sleep(pollInterval)
+		}
+	}
+}
+
+func processNextJob() (processed bool) {
+
+	job, err := claimNextJob()
+	if err != nil {
+		NewInternalError("could not claim next job", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	err = runJob(*job)
+	if err == nil {
+		completeJob(*job)
+	} else {
+		failJob(*job, err)
+	}
+
+	return true
+}
+
+// Other workers skip the locked row, so every job is claimed by exactly one worker
+func claimNextJob() (*model.Job, error) {
+
+	var claimedJob *model.Job
+	claimTask := func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var jobs []model.Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)", model.JobStatusPending, now, model.JobStatusRunning, now).
+			Order("run_at asc").
+			Limit(1).
+			Find(&jobs).Error
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		job := jobs[0]
+
+		// A job that crashed the worker on its last attempt must not run again
+		if job.Status == model.JobStatusRunning && job.Attempts >= job.MaxAttempts {
+			lastError := fmt.Sprintf("timed out after %s", jobTimeout)
+			job.Status = model.JobStatusDead
+			job.LastError = &lastError
+			job.LockedUntil = nil
+			NewInternalError(fmt.Sprintf("job %d of type %s timed out on its last attempt and will not be retried", job.ID, job.JobType), nil)
+
+			return tx.Save(&job).Error
+		}
+
+		lockedUntil := now.Add(jobTimeout)
+		job.Status = model.JobStatusRunning
+		job.Attempts++
+		job.LockedUntil = &lockedUntil
+
+		err = tx.Save(&job).Error
+		if err != nil {
+			return err
+		}
+
+		claimedJob = &job
+		return nil
+	}
+
+	err := database.GetConnection().Transaction(claimTask)
+	return claimedJob, err
+}
+
+func runJob(job model.Job) error {
+
+	handlers.RLock()
+	handler, found := handlers.byType[job.JobType]
+	handlers.RUnlock()
+
+	if !found {
+		return fmt.Errorf("no handler registered for job type %s", job.JobType)
+	}
+
+	return handler(job.Payload)
+}
+
+func completeJob(job model.Job) {
+
+	err := database.GetConnection().Unscoped().Delete(&job).Error
+	if err != nil {
+		NewInternalError(fmt.Sprintf("could not delete completed job %d", job.ID), err)
+	}
+}
+
+func failJob(job model.Job, cause error) {
+
+	lastError := cause.Error()
+	job.LastError = &lastError
+	job.LockedUntil = nil
+
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = model.JobStatusDead
+		NewInternalError(fmt.Sprintf("job %d of type %s failed %d times and will not be retried", job.ID, job.JobType, job.Attempts), cause)
+	} else {
+		job.Status = model.JobStatusPending
+		job.RunAt = time.Now().Add(retryBackoff(job.Attempts))
+		logger.Warningf("Job %d of type %s failed (attempt %d of %d), retrying at %s: %s", job.ID, job.JobType, job.Attempts, job.MaxAttempts, job.RunAt.Format(time.RFC3339), lastError)
+	}
+
+	err := database.GetConnection().Save(&job).Error
+	if err != nil {
+		NewInternalError(fmt.Sprintf("could not save failed job %d", job.ID), err)
+	}
+}
+
+// Doubles with every attempt: 30s, 1m, 2m, 4m, ... up to an hour
+func retryBackoff(attempts uint) time.Duration {
+
+	backoff := time.Duration(float64(minRetryBackoff) * math.Pow(2, float64(attempts-1)))
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+
+	return backoff
+}
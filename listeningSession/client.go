@@ -2,10 +2,10 @@ package listeningSession
 
 import (
 	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/spotifyApi"
 	"github.com/partyoffice/spotifete/users"
-	"github.com/zmb3/spotify"
 )
 
-func Client(session model.FullListeningSession) *spotify.Client {
+func Client(session model.FullListeningSession) spotifyApi.Client {
 	return users.Client(session.Owner)
 }
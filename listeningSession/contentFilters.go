@@ -0,0 +1,64 @@
+package listeningSession
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/zmb3/spotify"
+)
+
+func SetContentFilters(session model.SimpleListeningSession, user model.SimpleUser, filters model.ContentFilters) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the content filters.")
+	}
+
+	session.ContentFilters = filters
+	database.GetConnection().Model(&session).Updates(map[string]interface{}{
+		"allow_explicit":             filters.AllowExplicit,
+		"max_track_duration_seconds": filters.MaxTrackDurationSeconds,
+	})
+
+	return nil
+}
+
+func checkContentFilters(session model.SimpleListeningSession, track spotify.FullTrack) *SpotifeteError {
+
+	violation := findContentFilterViolation(session, track)
+	if violation != "" {
+		return NewUserError(violation)
+	}
+
+	return nil
+}
+
+// Returns a message for the guest if the track is filtered and an empty string otherwise
+func findContentFilterViolation(session model.SimpleListeningSession, track spotify.FullTrack) string {
+
+	if !session.AllowExplicit && track.Explicit {
+		return "Explicit tracks are not allowed in this session."
+	}
+
+	maxTrackDuration := time.Duration(session.MaxTrackDurationSeconds) * time.Second
+	if maxTrackDuration > 0 && time.Duration(track.Duration)*time.Millisecond > maxTrackDuration {
+		return fmt.Sprintf("Tracks longer than %d:%02d are not allowed in this session.",
+			session.MaxTrackDurationSeconds/60,
+			session.MaxTrackDurationSeconds%60)
+	}
+
+	return ""
+}
+
+func filterTracksAllowedByContentFilters(tracks []spotify.FullTrack, session model.SimpleListeningSession) []spotify.FullTrack {
+
+	var allowedTracks []spotify.FullTrack
+	for _, track := range tracks {
+		if findContentFilterViolation(session, track) == "" {
+			allowedTracks = append(allowedTracks, track)
+		}
+	}
+
+	return allowedTracks
+}
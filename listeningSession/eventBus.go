@@ -0,0 +1,102 @@
+package listeningSession
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+// Domain events are published once the change they describe has been saved.
+// Every subscriber runs in its own goroutine, so a slow integration can not hold up requests or the poller.
+
+type SessionCreated struct {
+	Session model.SimpleListeningSession
+}
+
+type SongRequested struct {
+	Session model.FullListeningSession
+	Request model.SongRequest
+	// Requesting a track that is already in the queue only upvotes the existing request
+	UpvotedDuplicate bool
+	Queue            []model.SongRequest
+}
+
+// Published for requests of moderated sessions once the owner let them into the queue
+type RequestApproved struct {
+	Session model.FullListeningSession
+	Request model.SongRequest
+}
+
+// Published when the owner changed the queue, so the playback has to be synced with it
+type QueueChanged struct {
+	Session model.FullListeningSession
+}
+
+type RequestDeleted struct {
+	Session model.SimpleListeningSession
+	Request model.SongRequest
+}
+
+type QueueAdvanced struct {
+	Session       model.FullListeningSession
+	PlayedRequest model.SongRequest
+	Queue         []model.SongRequest
+}
+
+type FallbackTrackAdded struct {
+	Session model.FullListeningSession
+	Request model.SongRequest
+}
+
+type SessionClosed struct {
+	Session model.FullListeningSession
+}
+
+var eventSubscribers = struct {
+	sync.RWMutex
+	handlers map[reflect.Type][]func(event any)
+}{
+	handlers: map[reflect.Type][]func(event any){},
+}
+
+// Registers a handler for all events of type E. Subscribers can not be removed again.
+func Subscribe[E any](handler func(event E)) {
+
+	eventType := reflect.TypeFor[E]()
+
+	eventSubscribers.Lock()
+	defer eventSubscribers.Unlock()
+
+	eventSubscribers.handlers[eventType] = append(eventSubscribers.handlers[eventType], func(event any) {
+		handler(event.(E))
+	})
+}
+
+func publish[E any](event E) {
+
+	eventSubscribers.RLock()
+	handlers := eventSubscribers.handlers[reflect.TypeFor[E]()]
+	eventSubscribers.RUnlock()
+
+	for _, handler := range handlers {
+		go handler(event)
+	}
+}
+
+var setupOnce sync.Once
+
+// Registers the subscribers and job handlers SpotiFete itself relies on. Has to be called before any session is used.
+func Setup() {
+	setupOnce.Do(func() {
+		Subscribe(func(event SongRequested) {
+			syncPlaybackWithLatestQueue(event.Session)
+		})
+		Subscribe(func(event QueueChanged) {
+			syncPlaybackWithLatestQueue(event.Session)
+		})
+
+		subscribeLiveUpdates()
+		registerJobHandlers()
+	})
+}
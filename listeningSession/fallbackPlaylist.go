@@ -14,6 +14,8 @@ import (
 	"github.com/zmb3/spotify"
 )
 
+const fallbackPlaylistRequester = "Fallback-Playlist"
+
 func ChangeFallbackPlaylist(session model.SimpleListeningSession, user model.SimpleUser, playlistId string) *SpotifeteError {
 	if session.OwnerId != user.ID {
 		return NewUserError("Only the session owner can change the fallback playlist.")
@@ -28,6 +30,8 @@ func ChangeFallbackPlaylist(session model.SimpleListeningSession, user model.Sim
 	session.FallbackPlaylistId = &playlistMetadata.SpotifyPlaylistId
 	database.GetConnection().Save(session)
 
+	publishSessionEvent(session, EventFallbackPlaylistChanged, "")
+
 	return nil
 }
 
@@ -39,6 +43,8 @@ func RemoveFallbackPlaylist(session model.SimpleListeningSession, user model.Sim
 	session.FallbackPlaylistId = nil
 	database.GetConnection().Save(session)
 
+	publishSessionEvent(session, EventFallbackPlaylistChanged, "")
+
 	return nil
 }
 
@@ -64,7 +70,7 @@ func addFallbackTrackIfNecessary(session model.FullListeningSession, queue []mod
 		return queue, nil
 	}
 
-	for i := len(queue); i < 2; i++ {
+	for i := len(queue); i < queueLookahead(session.SimpleListeningSession); i++ {
 		addedRequest, spotifeteError := addFallbackTrack(session)
 		if spotifeteError != nil {
 			return queue, spotifeteError
@@ -83,23 +89,40 @@ func addFallbackTrack(session model.FullListeningSession) (addedRequest model.So
 		return model.SongRequest{}, spotifeteError
 	}
 
-	addedRequest, spotifeteError = RequestSong(session, fallbackTrackId, "Fallback-Playlist")
+	addedRequest, spotifeteError = requestSong(session, fallbackTrackId, nil)
 	if spotifeteError != nil {
 		return model.SongRequest{}, spotifeteError
 	}
 
+	publish(FallbackTrackAdded{Session: session, Request: addedRequest})
+
 	return addedRequest, nil
 }
 
 func findNextFallbackTrack(session model.FullListeningSession) (nextFallbackTrackId string, spotifeteError *SpotifeteError) {
 
-	playableTracks, spotifeteError := getPlayablePlaylistTracks(*session.FallbackPlaylistId, session.Owner)
+	playableTracks, spotifeteError := getPlayablePlaylistTracks(*session.FallbackPlaylistId, session.Owner, Market(session))
 	if spotifeteError != nil {
 		return "", spotifeteError
 	}
 
 	if len(*playableTracks) >= 0 {
-		return doFindNextFallbackTrack(playableTracks, session)
+		rules, spotifeteError := GetSessionRules(session.SimpleListeningSession)
+		if spotifeteError != nil {
+			return "", spotifeteError
+		}
+
+		allowedTracks := filterTracksAllowedBySessionRules(*playableTracks, rules)
+		if len(allowedTracks) == 0 {
+			return "", NewUserError("The session rules do not allow any track from the fallback playlist.")
+		}
+
+		allowedTracks = filterTracksAllowedByContentFilters(allowedTracks, session.SimpleListeningSession)
+		if len(allowedTracks) == 0 {
+			return "", NewUserError("The content filters do not allow any track from the fallback playlist.")
+		}
+
+		return doFindNextFallbackTrack(&allowedTracks, session)
 	}
 
 	session.FallbackPlaylistId = nil
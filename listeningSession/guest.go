@@ -0,0 +1,141 @@
+package listeningSession
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/partyoffice/spotifete/authentication"
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	// Every new guest starts with fresh request limits, so a client must not be able to create guests over and over
+	maxNewGuestsPerClient   = 3
+	newGuestRateLimitWindow = time.Hour
+)
+
+var recentGuestCreations = cache.New(newGuestRateLimitWindow, 10*time.Minute)
+
+// Joining again with a valid guest token keeps the guest and only changes its display name.
+// The client address is used to limit how many new guests one client can create in a session.
+func JoinSession(session model.SimpleListeningSession, displayName string, existingGuestToken string, clientAddress string) (guest model.Guest, guestToken string, spotifeteError *SpotifeteError) {
+
+	cleanedDisplayName, spotifeteError := cleanDisplayName(displayName)
+	if spotifeteError != nil {
+		return model.Guest{}, "", spotifeteError
+	}
+
+	if existingGuestToken != "" {
+		existingGuest, spotifeteError := FindGuestForToken(session, existingGuestToken)
+		if spotifeteError == nil {
+			return rejoinSession(*existingGuest, cleanedDisplayName, existingGuestToken)
+		}
+	}
+
+	spotifeteError = countNewGuest(session, clientAddress)
+	if spotifeteError != nil {
+		return model.Guest{}, "", spotifeteError
+	}
+
+	guest = model.Guest{
+		SessionId:   session.ID,
+		DisplayName: cleanedDisplayName,
+	}
+
+	err := database.GetConnection().Create(&guest).Error
+	if err != nil {
+		return model.Guest{}, "", NewInternalError("Could not save new guest", err)
+	}
+
+	return guest, authentication.NewGuestToken(guest.ID, session.ID), nil
+}
+
+func rejoinSession(guest model.Guest, displayName string, guestToken string) (model.Guest, string, *SpotifeteError) {
+
+	if guest.DisplayName != displayName {
+		err := database.GetConnection().Model(&guest).Update("display_name", displayName).Error
+		if err != nil {
+			return model.Guest{}, "", NewInternalError("Could not update display name of guest", err)
+		}
+	}
+
+	return guest, guestToken, nil
+}
+
+func countNewGuest(session model.SimpleListeningSession, clientAddress string) *SpotifeteError {
+
+	cacheKey := fmt.Sprintf("%d:%s", session.ID, clientAddress)
+	err := recentGuestCreations.Add(cacheKey, 1, cache.DefaultExpiration)
+	if err == nil {
+		return nil
+	}
+
+	newGuestCount, err := recentGuestCreations.IncrementInt(cacheKey, 1)
+	if err != nil {
+		// The entry expired in the meantime
+		return nil
+	}
+
+	if newGuestCount > maxNewGuestsPerClient {
+		return NewUserError("You joined this session too often. Please try again later.")
+	}
+
+	return nil
+}
+
+func cleanDisplayName(rawDisplayName string) (cleanedDisplayName string, err *SpotifeteError) {
+
+	trimmedDisplayName := strings.TrimSpace(rawDisplayName)
+	if len(trimmedDisplayName) == 0 {
+		return "", NewUserError("Display name must not be empty.")
+	}
+
+	if len(trimmedDisplayName) > 63 {
+		return "", NewUserError("Display name must not be longer than 63 characters.")
+	}
+
+	if trimmedDisplayName == fallbackPlaylistRequester {
+		return "", NewUserError("This display name is reserved.")
+	}
+
+	return trimmedDisplayName, nil
+}
+
+func FindGuestForToken(session model.SimpleListeningSession, guestToken string) (*model.Guest, *SpotifeteError) {
+
+	guestId, spotifeteError := authentication.GuestIdFromToken(guestToken, session.ID)
+	if spotifeteError != nil {
+		return nil, spotifeteError
+	}
+
+	var guests []model.Guest
+	database.GetConnection().Where(model.Guest{
+		BaseModel: model.BaseModel{ID: guestId},
+		SessionId: session.ID,
+	}).Find(&guests)
+
+	resultCount := len(guests)
+	if resultCount == 1 {
+		return &guests[0], nil
+	} else if resultCount == 0 {
+		return nil, NewUserError("Unknown guest.")
+	} else {
+		return nil, NewInternalError(fmt.Sprintf("Got more than one guest for id %d", guestId), nil)
+	}
+}
+
+func GetQueuedRequestsOfGuest(session model.SimpleListeningSession, guest model.Guest) ([]model.SongRequest, error) {
+
+	filter := map[string]interface{}{
+		"session_id": session.ID,
+		"guest_id":   guest.ID,
+		"played":     false,
+	}
+	query := database.GetConnection().Where(filter).Order("song_requests.created_at asc")
+
+	return FindSongRequests(query)
+}
@@ -0,0 +1,79 @@
+package listeningSession
+
+import (
+	"testing"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+func TestFourthNewGuestOfClientIsRefused(t *testing.T) {
+
+	session := model.SimpleListeningSession{BaseModel: model.BaseModel{ID: 4000001}}
+	otherSession := model.SimpleListeningSession{BaseModel: model.BaseModel{ID: 4000002}}
+
+	for i := 1; i <= maxNewGuestsPerClient; i++ {
+		if spotifeteError := countNewGuest(session, "10.0.0.1"); spotifeteError != nil {
+			t.Fatalf("new guest %d was refused: %s", i, spotifeteError.MessageForUser)
+		}
+	}
+
+	if spotifeteError := countNewGuest(session, "10.0.0.1"); spotifeteError == nil {
+		t.Errorf("new guest %d was accepted, want it refused", maxNewGuestsPerClient+1)
+	}
+	if spotifeteError := countNewGuest(session, "10.0.0.2"); spotifeteError != nil {
+		t.Errorf("new guest of another client was refused: %s", spotifeteError.MessageForUser)
+	}
+	if spotifeteError := countNewGuest(otherSession, "10.0.0.1"); spotifeteError != nil {
+		t.Errorf("new guest of the client in another session was refused: %s", spotifeteError.MessageForUser)
+	}
+}
+
+func TestRejoiningWithGuestTokenKeepsGuest(t *testing.T) {
+
+	testSession := newTestSession(t)
+	clientAddress := uniqueId(t, "client")
+
+	guest, guestToken, spotifeteError := JoinSession(testSession.session.SimpleListeningSession, "Guest", "", clientAddress)
+	if spotifeteError != nil {
+		t.Fatalf("could not join session: %s", spotifeteError.MessageForUser)
+	}
+
+	// Rejoining does not count as a new guest, so the client can do it more often than it can create guests
+	for i := 0; i <= maxNewGuestsPerClient; i++ {
+		rejoinedGuest, rejoinedGuestToken, spotifeteError := JoinSession(testSession.session.SimpleListeningSession, "Renamed guest", guestToken, clientAddress)
+		if spotifeteError != nil {
+			t.Fatalf("could not rejoin session: %s", spotifeteError.MessageForUser)
+		}
+		if rejoinedGuest.ID != guest.ID || rejoinedGuestToken != guestToken {
+			t.Fatalf("rejoining created guest %d, want guest %d", rejoinedGuest.ID, guest.ID)
+		}
+	}
+
+	foundGuest, spotifeteError := FindGuestForToken(testSession.session.SimpleListeningSession, guestToken)
+	if spotifeteError != nil {
+		t.Fatalf("could not find guest for token: %s", spotifeteError.MessageForUser)
+	}
+	if foundGuest.DisplayName != "Renamed guest" {
+		t.Errorf("guest is called %q, want %q", foundGuest.DisplayName, "Renamed guest")
+	}
+}
+
+func TestJoiningWithTokenOfOtherSessionCreatesNewGuest(t *testing.T) {
+
+	testSession := newTestSession(t)
+	otherSession := newTestSession(t)
+	clientAddress := uniqueId(t, "client")
+
+	otherGuest, otherGuestToken, spotifeteError := JoinSession(otherSession.session.SimpleListeningSession, "Guest", "", clientAddress)
+	if spotifeteError != nil {
+		t.Fatalf("could not join other session: %s", spotifeteError.MessageForUser)
+	}
+
+	guest, _, spotifeteError := JoinSession(testSession.session.SimpleListeningSession, "Guest", otherGuestToken, clientAddress)
+	if spotifeteError != nil {
+		t.Fatalf("could not join session: %s", spotifeteError.MessageForUser)
+	}
+	if guest.ID == otherGuest.ID || guest.SessionId != testSession.session.ID {
+		t.Errorf("joined as guest %d of session %d, want a new guest of session %d", guest.ID, guest.SessionId, testSession.session.ID)
+	}
+}
@@ -0,0 +1,50 @@
+package listeningSession
+
+import (
+	"fmt"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+// Returns the played requests of the session, most recently played first, and the total number of played requests.
+// Pages start at 1.
+func GetPlayHistory(session model.SimpleListeningSession, page int, pageSize int) ([]model.SongRequest, int64, *SpotifeteError) {
+	if page < 1 {
+		return nil, 0, NewUserError("Page must be at least 1.")
+	}
+	if pageSize < 1 || pageSize > MaxHistoryPageSize {
+		return nil, 0, NewUserError(fmt.Sprintf("Page size must be between 1 and %d.", MaxHistoryPageSize))
+	}
+
+	filter := map[string]interface{}{
+		"session_id": session.ID,
+		"played":     true,
+	}
+
+	var totalCount int64
+	err := database.GetConnection().Model(model.SongRequest{}).Where(filter).Count(&totalCount).Error
+	if err != nil {
+		return nil, 0, NewInternalError("Could not count played requests", err)
+	}
+
+	// Requests without a timestamp were played before it was recorded and are sorted to the end
+	query := database.GetConnection().
+		Where(filter).
+		Order("song_requests.played_at desc nulls last").
+		Order("song_requests.id desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize)
+	history, err := FindSongRequests(query)
+	if err != nil {
+		return nil, 0, NewInternalError("Could not fetch play history from database", err)
+	}
+
+	return history, totalCount, nil
+}
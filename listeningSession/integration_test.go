@@ -0,0 +1,474 @@
+package listeningSession
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/partyoffice/spotifete/authentication"
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/spotifyApi/fake"
+	"github.com/zmb3/spotify"
+)
+
+// The integration tests run against a real database and the fake Spotify API. They are skipped unless
+// SPOTIFETE_TEST_CONFIG_DIRECTORY points to a directory with a spotifete-config.yml for a database that may be written to.
+const testConfigDirectoryVariable = "SPOTIFETE_TEST_CONFIG_DIRECTORY"
+
+var (
+	fakeSpotify          *fake.Server
+	integrationTestSetup sync.Once
+)
+
+func TestMain(m *testing.M) {
+
+	code := m.Run()
+	if fakeSpotify != nil {
+		fakeSpotify.Close()
+	}
+
+	os.Exit(code)
+}
+
+func setupIntegrationTest(t *testing.T) {
+
+	t.Helper()
+
+	configDirectory := os.Getenv(testConfigDirectoryVariable)
+	if configDirectory == "" {
+		t.Skipf("%s is not set", testConfigDirectoryVariable)
+	}
+
+	integrationTestSetup.Do(func() {
+		_ = os.Setenv("SPOTIFETE_CONFIG_DIRECTORY", configDirectory)
+
+		// The migrations are read relative to the repository root
+		err := os.Chdir("..")
+		if err != nil {
+			panic(err)
+		}
+
+		fakeSpotify = fake.NewServer()
+		authentication.SetClientFactory(fakeSpotify.NewClient)
+
+		for i := 1; i <= 6; i++ {
+			fakeSpotify.AddTrack(fake.NewTrack(fmt.Sprintf("track%d", i), fmt.Sprintf("Track %d", i), "Artist", "Album", 180000))
+			explicitTrack := fake.NewTrack(fmt.Sprintf("explicit%d", i), fmt.Sprintf("Explicit %d", i), "Artist", "Album", 180000)
+			explicitTrack.Explicit = true
+			fakeSpotify.AddTrack(explicitTrack)
+		}
+	})
+}
+
+// Spotify ids and client addresses have to be unique, because the database is shared with earlier test runs
+func uniqueId(t *testing.T, prefix string) string {
+	return fmt.Sprintf("%s-%s-%d", prefix, t.Name(), time.Now().UnixNano())
+}
+
+type testSession struct {
+	owner    model.SimpleUser
+	deviceId spotify.ID
+	session  model.FullListeningSession
+}
+
+func newTestSession(t *testing.T) testSession {
+
+	t.Helper()
+	setupIntegrationTest(t)
+
+	spotifyId := uniqueId(t, "owner")
+	token := fakeSpotify.AddUser(spotifyId, "Owner", "DE", "premium")
+	deviceId := fakeSpotify.AddDevice(spotifyId, "Party speaker")
+
+	owner := model.SimpleUser{SpotifyId: spotifyId}.
+		SetProfile(spotify.PrivateUser{
+			User:    spotify.User{DisplayName: "Owner", ID: spotifyId},
+			Country: "DE",
+			Product: "premium",
+		}).
+		SetToken(token)
+	err := database.GetConnection().Create(&owner).Error
+	if err != nil {
+		t.Fatalf("could not create owner: %v", err)
+	}
+
+	createdSession, spotifeteError := NewSession(owner, "Test session")
+	if spotifeteError != nil {
+		t.Fatalf("could not create session: %s", spotifeteError.MessageForUser)
+	}
+
+	return testSession{
+		owner:    owner,
+		deviceId: deviceId,
+		session:  reloadSession(t, createdSession.ID),
+	}
+}
+
+func reloadSession(t *testing.T, sessionId uint) model.FullListeningSession {
+
+	t.Helper()
+
+	session := FindFullListeningSession(model.SimpleListeningSession{BaseModel: model.BaseModel{ID: sessionId}})
+	if session == nil {
+		t.Fatalf("session %d not found", sessionId)
+	}
+
+	return *session
+}
+
+func (testSession testSession) joinAsGuest(t *testing.T, displayName string) model.Guest {
+
+	t.Helper()
+
+	guest, _, spotifeteError := JoinSession(testSession.session.SimpleListeningSession, displayName, "", uniqueId(t, "client"))
+	if spotifeteError != nil {
+		t.Fatalf("could not join session: %s", spotifeteError.MessageForUser)
+	}
+
+	return guest
+}
+
+func (testSession testSession) request(t *testing.T, trackId string, guest model.Guest) model.SongRequest {
+
+	t.Helper()
+
+	request, spotifeteError := RequestSong(testSession.session, trackId, guest)
+	if spotifeteError != nil {
+		t.Fatalf("could not request %s: %s", trackId, spotifeteError.MessageForUser)
+	}
+
+	return request
+}
+
+func (testSession testSession) queuedTrackIds(t *testing.T) []string {
+
+	t.Helper()
+
+	queue, err := GetFullQueue(testSession.session.SimpleListeningSession)
+	if err != nil {
+		t.Fatalf("could not fetch queue: %v", err)
+	}
+
+	var trackIds []string
+	for _, request := range queue {
+		trackIds = append(trackIds, request.SpotifyTrackId)
+	}
+
+	return trackIds
+}
+
+func (testSession testSession) playlistTrackIds(t *testing.T) []string {
+
+	t.Helper()
+
+	playlist, found := fakeSpotify.Playlist(spotify.ID(testSession.session.QueuePlaylistId))
+	if !found {
+		t.Fatalf("queue playlist %s not found", testSession.session.QueuePlaylistId)
+	}
+
+	var trackIds []string
+	for _, trackId := range playlist.Tracks {
+		trackIds = append(trackIds, trackId.String())
+	}
+
+	return trackIds
+}
+
+func (testSession testSession) setFallbackPlaylist(t *testing.T, trackIds ...spotify.ID) testSession {
+
+	t.Helper()
+
+	playlistId := fakeSpotify.AddPlaylist(testSession.owner.SpotifyId, "Fallback", trackIds...)
+	spotifeteError := ChangeFallbackPlaylist(testSession.session.SimpleListeningSession, testSession.owner, playlistId.String())
+	if spotifeteError != nil {
+		t.Fatalf("could not change fallback playlist: %s", spotifeteError.MessageForUser)
+	}
+
+	testSession.session = reloadSession(t, testSession.session.ID)
+	return testSession
+}
+
+func assertTrackIds(t *testing.T, what string, got []string, want ...string) {
+
+	t.Helper()
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%s are %v, want %v", what, got, want)
+	}
+}
+
+func TestRequestSongAddsTrackToQueue(t *testing.T) {
+
+	testSession := newTestSession(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+
+	request := testSession.request(t, "track1", guest)
+
+	if request.SpotifyTrackId != "track1" || request.RequestedBy != "Guest" || request.FromFallback {
+		t.Errorf("got request %+v, want track1 requested by Guest", request)
+	}
+	if !request.Locked {
+		t.Errorf("first request is not locked, want it locked because it is within the lookahead")
+	}
+	if request.TrackMetadata.DurationMs != 180000 {
+		t.Errorf("request has a duration of %dms, want 180000ms", request.TrackMetadata.DurationMs)
+	}
+
+	testSession.request(t, "track2", guest)
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track1", "track2")
+}
+
+func TestRequestSongVotesForDuplicates(t *testing.T) {
+
+	testSession := newTestSession(t)
+	firstGuest := testSession.joinAsGuest(t, "First guest")
+	secondGuest := testSession.joinAsGuest(t, "Second guest")
+
+	// Requests within the lookahead can not be voted on, so the duplicate has to be further back
+	testSession.request(t, "track1", firstGuest)
+	testSession.request(t, "track2", firstGuest)
+	testSession.request(t, "track3", firstGuest)
+	duplicate := testSession.request(t, "track3", secondGuest)
+
+	if duplicate.RequestedBy != "First guest" || duplicate.VoteScore != 1 {
+		t.Errorf("got request %+v, want the request of the first guest with one vote", duplicate)
+	}
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track1", "track2", "track3")
+}
+
+func TestRequestSongRejectsUnknownTrack(t *testing.T) {
+
+	testSession := newTestSession(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+
+	_, spotifeteError := RequestSong(testSession.session, "unknown", guest)
+	if spotifeteError == nil {
+		t.Fatalf("requesting an unknown track succeeded")
+	}
+
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t))
+}
+
+func TestQueueAdvancesWhenTrackFinishes(t *testing.T) {
+
+	testSession := newTestSession(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+	firstRequest := testSession.request(t, "track1", guest)
+	testSession.request(t, "track2", guest)
+	testSession.request(t, "track3", guest)
+
+	spotifeteError := StartPlayback(testSession.session, testSession.owner, testSession.deviceId.String())
+	if spotifeteError != nil {
+		t.Fatalf("could not start playback: %s", spotifeteError.MessageForUser)
+	}
+	assertTrackIds(t, "playlist tracks", testSession.playlistTrackIds(t), "track1", "track2")
+
+	fakeSpotify.FinishTrack(testSession.owner.SpotifyId)
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track2", "track3")
+	assertTrackIds(t, "playlist tracks", testSession.playlistTrackIds(t), "track2", "track3")
+
+	playedRequest, err := FindSongRequest(model.SongRequest{BaseModel: model.BaseModel{ID: firstRequest.ID}})
+	if err != nil || playedRequest == nil {
+		t.Fatalf("could not fetch first request: %v", err)
+	}
+	if !playedRequest.Played || playedRequest.PlayedAt == nil {
+		t.Errorf("first request is not marked as played")
+	}
+}
+
+func TestQueueDoesNotAdvanceWhileTrackIsPlaying(t *testing.T) {
+
+	testSession := newTestSession(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+	testSession.request(t, "track1", guest)
+	testSession.request(t, "track2", guest)
+
+	spotifeteError := StartPlayback(testSession.session, testSession.owner, testSession.deviceId.String())
+	if spotifeteError != nil {
+		t.Fatalf("could not start playback: %s", spotifeteError.MessageForUser)
+	}
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track1", "track2")
+}
+
+func TestFallbackPlaylistFillsQueue(t *testing.T) {
+
+	testSession := newTestSession(t).setFallbackPlaylist(t, "track1", "track2", "track3", "track4")
+
+	spotifeteError := UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	queue, err := GetFullQueue(testSession.session.SimpleListeningSession)
+	if err != nil {
+		t.Fatalf("could not fetch queue: %v", err)
+	}
+	if len(queue) != defaultQueueLookahead {
+		t.Fatalf("queue has %d requests, want %d", len(queue), defaultQueueLookahead)
+	}
+	if queue[0].SpotifyTrackId == queue[1].SpotifyTrackId {
+		t.Errorf("the same fallback track was added twice")
+	}
+	for _, request := range queue {
+		if !request.FromFallback || request.RequestedBy != fallbackPlaylistRequester {
+			t.Errorf("request %s is not from the fallback playlist", request.SpotifyTrackId)
+		}
+	}
+}
+
+func TestFallbackPlaylistIsNotUsedWhileQueueIsFull(t *testing.T) {
+
+	testSession := newTestSession(t).setFallbackPlaylist(t, "track3", "track4", "track5")
+	guest := testSession.joinAsGuest(t, "Guest")
+	testSession.request(t, "track1", guest)
+	testSession.request(t, "track2", guest)
+
+	spotifeteError := UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track1", "track2")
+}
+
+func TestFallbackPlaylistRespectsContentFilters(t *testing.T) {
+
+	testSession := newTestSession(t).setFallbackPlaylist(t, "explicit1", "track1", "explicit2", "track2", "explicit3")
+	spotifeteError := SetContentFilters(testSession.session.SimpleListeningSession, testSession.owner, model.ContentFilters{AllowExplicit: false})
+	if spotifeteError != nil {
+		t.Fatalf("could not set content filters: %s", spotifeteError.MessageForUser)
+	}
+	testSession.session = reloadSession(t, testSession.session.ID)
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	queuedTrackIds := testSession.queuedTrackIds(t)
+	if len(queuedTrackIds) != defaultQueueLookahead {
+		t.Fatalf("queue has %d requests, want %d", len(queuedTrackIds), defaultQueueLookahead)
+	}
+	for _, trackId := range queuedTrackIds {
+		if trackId != "track1" && trackId != "track2" {
+			t.Errorf("explicit fallback track %s was added to the queue", trackId)
+		}
+	}
+}
+
+func TestFallbackPlaylistIsKeptIfContentFiltersAllowNoTrack(t *testing.T) {
+
+	testSession := newTestSession(t).setFallbackPlaylist(t, "explicit1", "explicit2", "explicit3")
+	spotifeteError := SetContentFilters(testSession.session.SimpleListeningSession, testSession.owner, model.ContentFilters{AllowExplicit: false})
+	if spotifeteError != nil {
+		t.Fatalf("could not set content filters: %s", spotifeteError.MessageForUser)
+	}
+	testSession.session = reloadSession(t, testSession.session.ID)
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError == nil {
+		t.Fatalf("updating the session succeeded, want an error because no fallback track is allowed")
+	}
+
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t))
+	if reloadSession(t, testSession.session.ID).FallbackPlaylistId == nil {
+		t.Errorf("fallback playlist was removed")
+	}
+}
+
+func (testSession testSession) enableAutoRepairPlayback(t *testing.T) testSession {
+
+	t.Helper()
+
+	spotifeteError := SetAutoRepairPlayback(testSession.session.SimpleListeningSession, testSession.owner, true)
+	if spotifeteError != nil {
+		t.Fatalf("could not enable auto repair: %s", spotifeteError.MessageForUser)
+	}
+
+	testSession.session = reloadSession(t, testSession.session.ID)
+	return testSession
+}
+
+func (testSession testSession) player(t *testing.T) fake.Player {
+
+	t.Helper()
+
+	user, found := fakeSpotify.User(testSession.owner.SpotifyId)
+	if !found {
+		t.Fatalf("owner %s not found", testSession.owner.SpotifyId)
+	}
+
+	return user.Player
+}
+
+func TestWatchdogDoesNotRepeatFinishedLastTrack(t *testing.T) {
+
+	testSession := newTestSession(t).enableAutoRepairPlayback(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+	lastRequest := testSession.request(t, "track1", guest)
+
+	spotifeteError := StartPlayback(testSession.session, testSession.owner, testSession.deviceId.String())
+	if spotifeteError != nil {
+		t.Fatalf("could not start playback: %s", spotifeteError.MessageForUser)
+	}
+
+	fakeSpotify.FinishTrack(testSession.owner.SpotifyId)
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	if player := testSession.player(t); player.IsPlaying {
+		t.Errorf("player restarted %s, want it to stay stopped", player.CurrentTrack)
+	}
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t))
+
+	playedRequest, err := FindSongRequest(model.SongRequest{BaseModel: model.BaseModel{ID: lastRequest.ID}})
+	if err != nil || playedRequest == nil {
+		t.Fatalf("could not fetch last request: %v", err)
+	}
+	if !playedRequest.Played {
+		t.Errorf("last request is not marked as played")
+	}
+}
+
+func TestWatchdogRestartsWithRequestAfterFinishedTrack(t *testing.T) {
+
+	testSession := newTestSession(t).enableAutoRepairPlayback(t)
+	guest := testSession.joinAsGuest(t, "Guest")
+	testSession.request(t, "track1", guest)
+
+	spotifeteError := StartPlayback(testSession.session, testSession.owner, testSession.deviceId.String())
+	if spotifeteError != nil {
+		t.Fatalf("could not start playback: %s", spotifeteError.MessageForUser)
+	}
+
+	fakeSpotify.FinishTrack(testSession.owner.SpotifyId)
+	testSession.request(t, "track2", guest)
+
+	spotifeteError = UpdateSessionIfNecessary(testSession.session)
+	if spotifeteError != nil {
+		t.Fatalf("could not update session: %s", spotifeteError.MessageForUser)
+	}
+
+	if player := testSession.player(t); !player.IsPlaying || player.CurrentTrack != "track2" {
+		t.Errorf("player is playing %s (playing: %t), want track2", player.CurrentTrack, player.IsPlaying)
+	}
+	assertTrackIds(t, "queued tracks", testSession.queuedTrackIds(t), "track2")
+}
@@ -0,0 +1,98 @@
+package listeningSession
+
+import (
+	"fmt"
+
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/jobs"
+	"github.com/partyoffice/spotifete/users"
+	"github.com/zmb3/spotify"
+	"gorm.io/gorm"
+)
+
+// Spotify side effects that nobody waits for run as jobs, so they are retried instead of being lost.
+// Syncing the playback is not a job: changes to the queue publish an event once they are committed, whose subscriber syncs
+// the latest queue under the session lock. If that fails, the next poll syncs it again anyway.
+const (
+	jobSetQueuePlaylistImage = "SET_QUEUE_PLAYLIST_IMAGE"
+	jobUnfollowQueuePlaylist = "UNFOLLOW_QUEUE_PLAYLIST"
+	jobCreateRewindPlaylist  = "CREATE_REWIND_PLAYLIST"
+	// Enqueued once by a migration
+	jobRefreshLegacyTrackMetadata = "REFRESH_LEGACY_TRACK_METADATA"
+)
+
+type setQueuePlaylistImagePayload struct {
+	OwnerId    uint   `json:"owner_id"`
+	PlaylistId string `json:"playlist_id"`
+	JoinId     string `json:"join_id"`
+}
+
+type sessionJobPayload struct {
+	SessionId uint `json:"session_id"`
+}
+
+func registerJobHandlers() {
+	jobs.Register(jobSetQueuePlaylistImage, func(payload setQueuePlaylistImagePayload) error {
+		owner := users.FindSimpleUser(model.SimpleUser{BaseModel: model.BaseModel{ID: payload.OwnerId}})
+		if owner == nil {
+			return fmt.Errorf("owner %d of queue playlist not found", payload.OwnerId)
+		}
+
+		spotifeteError := setPlaylistImage(spotify.ID(payload.PlaylistId), payload.JoinId, *owner)
+		if spotifeteError != nil {
+			return fmt.Errorf("could not set queue playlist image: %s", spotifeteError.MessageForUser)
+		}
+
+		return nil
+	})
+	jobs.Register(jobUnfollowQueuePlaylist, func(payload sessionJobPayload) error {
+		session, err := findSessionForJob(payload)
+		if err != nil {
+			return err
+		}
+
+		return tryUnfollowQueuePlaylistIfNecessary(*session)
+	})
+	jobs.Register(jobCreateRewindPlaylist, func(payload sessionJobPayload) error {
+		session, err := findSessionForJob(payload)
+		if err != nil {
+			return err
+		}
+
+		return tryCreateRewindPlaylistIfNecessary(*session)
+	})
+	jobs.Register(jobRefreshLegacyTrackMetadata, func(_ struct{}) error {
+		return refreshLegacyTrackMetadata()
+	})
+}
+
+func findSessionForJob(payload sessionJobPayload) (*model.FullListeningSession, error) {
+
+	session := FindFullListeningSession(model.SimpleListeningSession{BaseModel: model.BaseModel{ID: payload.SessionId}})
+	if session == nil {
+		return nil, fmt.Errorf("session %d not found", payload.SessionId)
+	}
+
+	return session, nil
+}
+
+func enqueueSetQueuePlaylistImageInTransaction(owner model.SimpleUser, playlistId spotify.ID, joinId string, tx *gorm.DB) error {
+
+	return jobs.EnqueueInTransaction(jobSetQueuePlaylistImage, setQueuePlaylistImagePayload{
+		OwnerId:    owner.ID,
+		PlaylistId: playlistId.String(),
+		JoinId:     joinId,
+	}, tx)
+}
+
+func enqueueSessionClosedJobsInTransaction(session model.SimpleListeningSession, tx *gorm.DB) error {
+
+	payload := sessionJobPayload{SessionId: session.ID}
+
+	err := jobs.EnqueueInTransaction(jobUnfollowQueuePlaylist, payload, tx)
+	if err != nil {
+		return err
+	}
+
+	return jobs.EnqueueInTransaction(jobCreateRewindPlaylist, payload, tx)
+}
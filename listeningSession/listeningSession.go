@@ -88,9 +88,29 @@ func NewSession(user model.SimpleUser, title string) (*model.SimpleListeningSess
 		QueuePlaylistId:         queuePlaylist.ID.String(),
 		Title:                   title,
 		FallbackPlaylistShuffle: true,
+		QueueStrategy:           defaultQueueStrategy,
+		PlaybackBackend:         defaultPlaybackBackend,
+		QueueLookahead:          defaultQueueLookahead,
+		RequestLimits:           model.RequestLimits{RequestWindowMinutes: 60},
+		ContentFilters:          model.ContentFilters{AllowExplicit: true},
+		PlaybackState:           PlaybackStateUnknown,
 	}
 
-	database.GetConnection().Create(&listeningSession)
+	createSessionTask := func(tx *gorm.DB) error {
+		err := tx.Create(&listeningSession).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueSetQueuePlaylistImageInTransaction(user, queuePlaylist.ID, joinId, tx)
+	}
+
+	err := database.GetConnection().Transaction(createSessionTask)
+	if err != nil {
+		return nil, NewInternalError("could not create session", err)
+	}
+
+	publish(SessionCreated{Session: listeningSession})
 
 	return &listeningSession, nil
 }
@@ -147,21 +167,25 @@ func CloseSession(user model.SimpleUser, joinId string) *SpotifeteError {
 		return NewUserError("Only the session owner can close a session.")
 	}
 
-	session.Active = false
-	database.GetConnection().Save(session)
-
-	go unfollowQueuePlaylistIfNecessary(*session)
-	go createRewindPlaylistIfNecessary(*session)
-
-	return nil
-}
+	closeSessionTask := func(tx *gorm.DB) error {
+		session.Active = false
+		err := tx.Save(session).Error
+		if err != nil {
+			return err
+		}
 
-func unfollowQueuePlaylistIfNecessary(session model.FullListeningSession) {
+		return enqueueSessionClosedJobsInTransaction(session.SimpleListeningSession, tx)
+	}
 
-	err := tryUnfollowQueuePlaylistIfNecessary(session)
+	err := database.GetConnection().Transaction(closeSessionTask)
 	if err != nil {
-		NewInternalError("could not unfollow queue playlist", err)
+		return NewInternalError("could not close session", err)
 	}
+
+	forgetSessionLock(session.SimpleListeningSession)
+	publish(SessionClosed{Session: *session})
+
+	return nil
 }
 
 func tryUnfollowQueuePlaylistIfNecessary(session model.FullListeningSession) error {
@@ -179,14 +203,6 @@ func tryUnfollowQueuePlaylistIfNecessary(session model.FullListeningSession) err
 	return nil
 }
 
-func createRewindPlaylistIfNecessary(session model.FullListeningSession) {
-
-	err := tryCreateRewindPlaylistIfNecessary(session)
-	if err != nil {
-		NewInternalError("could not create rewind playlist", err)
-	}
-}
-
 func tryCreateRewindPlaylistIfNecessary(session model.FullListeningSession) error {
 
 	distinctRequestedTracks := GetDistinctRequestedTracks(session.SimpleListeningSession)
@@ -200,26 +216,39 @@ func tryCreateRewindPlaylistIfNecessary(session model.FullListeningSession) erro
 	playlistName := fmt.Sprintf("%s Rewind - SpotiFete", session.Title)
 	playlistDescription := fmt.Sprintf("Rewind playlist for your session %s. This contains all the songs that were requested.", session.Title)
 
-	rewindPlaylist, err := client.CreatePlaylistForUser(ownerId, playlistName, playlistDescription, false)
-	if err != nil {
-		return err
-	}
+	// The playlist is saved before the tracks are added, so a retry fills the same playlist instead of creating another one
+	if session.RewindPlaylistId == nil {
+		rewindPlaylist, err := client.CreatePlaylistForUser(ownerId, playlistName, playlistDescription, false)
+		if err != nil {
+			return err
+		}
 
-	var page []spotify.ID
-	for _, track := range distinctRequestedTracks {
-		page = append(page, track)
+		rewindPlaylistId := rewindPlaylist.ID.String()
+		err = database.GetConnection().Model(&session.SimpleListeningSession).Update("rewind_playlist", rewindPlaylistId).Error
+		if err != nil {
+			return err
+		}
+		session.RewindPlaylistId = &rewindPlaylistId
+	}
+	rewindPlaylistId := spotify.ID(*session.RewindPlaylistId)
 
-		if len(page) == 100 {
-			_, err = client.AddTracksToPlaylist(rewindPlaylist.ID, page...)
-			if err != nil {
-				return err
-			}
-			page = []spotify.ID{}
+	// Replacing the tracks with the first page removes everything a failed attempt added before
+	var pages [][]spotify.ID
+	for start := 0; start < len(distinctRequestedTracks); start += 100 {
+		end := start + 100
+		if end > len(distinctRequestedTracks) {
+			end = len(distinctRequestedTracks)
 		}
+		pages = append(pages, distinctRequestedTracks[start:end])
+	}
+
+	err := client.ReplacePlaylistTracks(rewindPlaylistId, pages[0]...)
+	if err != nil {
+		return err
 	}
 
-	if len(page) > 0 {
-		_, err = client.AddTracksToPlaylist(rewindPlaylist.ID, page...)
+	for _, page := range pages[1:] {
+		_, err = client.AddTracksToPlaylist(rewindPlaylistId, page...)
 		if err != nil {
 			return err
 		}
@@ -228,62 +257,98 @@ func tryCreateRewindPlaylistIfNecessary(session model.FullListeningSession) erro
 	return nil
 }
 
-func RequestSong(session model.FullListeningSession, trackId string, username string) (createdRequest model.SongRequest, spotifeteError *SpotifeteError) {
+func RequestSong(session model.FullListeningSession, trackId string, guest model.Guest) (createdRequest model.SongRequest, spotifeteError *SpotifeteError) {
+	return requestSong(session, trackId, &guest)
+}
 
-	requestSongTask := func(tx *gorm.DB) error {
-		createdRequest, spotifeteError = createNewSongRequestInTransaction(session, trackId, username, tx)
+// The guest is nil for requests made by SpotiFete itself, e.g. from the fallback playlist
+func requestSong(session model.FullListeningSession, trackId string, guest *model.Guest) (createdRequest model.SongRequest, spotifeteError *SpotifeteError) {
+
+	var queue []model.SongRequest
+	requestSongTask := func(tx *gorm.DB) (err error) {
+		createdRequest, spotifeteError = createNewSongRequestInTransaction(session, trackId, guest, tx)
 		if spotifeteError != nil {
 			// TODO: improve this when refactoring errors
 			return errors.New("rolling back transaction")
 		}
 
-		queue, err := GetLimitedQueueInTransaction(session.SimpleListeningSession, 3, tx)
-		if err != nil {
-			return err
-		}
-
-		go updatePlaylistIfNecessary(session, queue)
-
-		return nil
+		queue, err = getQueueWithLookaheadInTransaction(session.SimpleListeningSession, tx)
+		return err
 	}
 
 	err := database.GetConnection().Transaction(requestSongTask)
+	if spotifeteError != nil {
+		return model.SongRequest{}, spotifeteError
+	}
 	if err != nil {
 		return model.SongRequest{}, NewInternalError("could not request song", err)
 	}
 
+	publish(SongRequested{
+		Session:          session,
+		Request:          createdRequest,
+		UpvotedDuplicate: guest != nil && !isRequestedBy(createdRequest, *guest),
+		Queue:            queue,
+	})
+
 	return createdRequest, nil
 }
 
-func createNewSongRequestInTransaction(session model.FullListeningSession, trackId string, username string, tx *gorm.DB) (model.SongRequest, *SpotifeteError) {
+func createNewSongRequestInTransaction(session model.FullListeningSession, trackId string, guest *model.Guest, tx *gorm.DB) (model.SongRequest, *SpotifeteError) {
 	client := Client(session)
 
-	if isTrackInQueue(session.SimpleListeningSession, trackId, tx) {
-		return model.SongRequest{}, NewUserError("This tack is already in the queue.")
+	duplicateRequest, err := findQueuedRequestForTrackInTransaction(session.SimpleListeningSession, trackId, tx)
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not fetch duplicates from database", err)
 	}
+	if duplicateRequest != nil {
+		if guest == nil {
+			return model.SongRequest{}, NewUserError("This track is already in the queue.")
+		}
 
-	currentUser, err := client.CurrentUser()
-	if err != nil {
-		return model.SongRequest{}, NewError("Could not get user information on session owner from Spotify.", err, http.StatusInternalServerError)
+		return voteForDuplicateRequestInTransaction(*duplicateRequest, *guest, tx)
 	}
 
-	spotifyTrack, err := client.GetTrackOpt(spotify.ID(trackId), &spotify.Options{
-		Country: &currentUser.Country,
-	})
+	trackIsPending, err := isTrackPendingInTransaction(session.SimpleListeningSession, trackId, tx)
 	if err != nil {
-		return model.SongRequest{}, NewError("Could not get track information from spotify.", err, http.StatusInternalServerError)
+		return model.SongRequest{}, NewInternalError("Could not fetch pending requests from database", err)
+	}
+	if trackIsPending {
+		return model.SongRequest{}, NewUserError("This track is already waiting for approval by the session owner.")
 	}
 
-	updatedTrackMetadata, err := AddOrUpdateTrackMetadataInTransaction(*spotifyTrack, tx)
-	if err != nil {
-		return model.SongRequest{}, NewInternalError("Could not get track metadata", err)
+	if guest != nil {
+		spotifeteError := checkRequestLimitsInTransaction(session.SimpleListeningSession, *guest, tx)
+		if spotifeteError != nil {
+			return model.SongRequest{}, spotifeteError
+		}
+	}
+
+	spotifyTrack, trackMetadata, spotifeteError := getTrackForRequestInTransaction(client, trackId, Market(session), tx)
+	if spotifeteError != nil {
+		return model.SongRequest{}, spotifeteError
 	}
 
 	if spotifyTrack.IsPlayable == nil || !*spotifyTrack.IsPlayable {
 		return model.SongRequest{}, NewUserError("Sorry, this track is not available :/")
 	}
 
-	weight, err := getRequestCountForUser(session.SimpleListeningSession, username, tx)
+	rules, err := findSessionRulesInTransaction(session.SimpleListeningSession, tx)
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not fetch session rules from database", err)
+	}
+
+	spotifeteError = checkSessionRules(rules, spotifyTrack)
+	if spotifeteError != nil {
+		return model.SongRequest{}, spotifeteError
+	}
+
+	spotifeteError = checkContentFilters(session.SimpleListeningSession, spotifyTrack)
+	if spotifeteError != nil {
+		return model.SongRequest{}, spotifeteError
+	}
+
+	weight, err := getRequestCountForRequester(session.SimpleListeningSession, guest, tx)
 	if err != nil {
 		return model.SongRequest{}, NewInternalError("Could not get number of requests for user.", err)
 	}
@@ -291,27 +356,35 @@ func createNewSongRequestInTransaction(session model.FullListeningSession, track
 	newSongRequest := model.SongRequest{
 		BaseModel:      model.BaseModel{},
 		SessionId:      session.ID,
-		RequestedBy:    username,
-		SpotifyTrackId: updatedTrackMetadata.SpotifyTrackId,
+		RequestedBy:    fallbackPlaylistRequester,
+		FromFallback:   true,
+		SpotifyTrackId: trackMetadata.SpotifyTrackId,
 		Weight:         weight,
+		ApprovalStatus: model.ApprovalStatusApproved,
 	}
+	if guest != nil {
+		newSongRequest.RequestedBy = guest.DisplayName
+		newSongRequest.FromFallback = false
+		newSongRequest.GuestId = &guest.ID
 
-	filter := map[string]interface{}{
-		"session_id": session.ID,
-		"played":     false,
-	}
-	queueLength, err := FindSongRequestCountInTransaction(filter, tx)
-	if err != nil {
-		return model.SongRequest{}, NewInternalError("Could not fetch duplicates from database", err)
+		// Tracks from the fallback playlist are chosen by the owner, so only guest requests need approval
+		if session.Moderated {
+			newSongRequest.ApprovalStatus = model.ApprovalStatusPending
+		}
 	}
-	if queueLength < 2 {
-		newSongRequest.Locked = true
+
+	if newSongRequest.ApprovalStatus == model.ApprovalStatusApproved {
+		err = lockRequestIfQueueIsShortInTransaction(session.SimpleListeningSession, &newSongRequest, tx)
+		if err != nil {
+			return model.SongRequest{}, NewInternalError("Could not get queue length from database", err)
+		}
 	}
 
 	err = tx.Create(&newSongRequest).Error
 	if err != nil {
 		return model.SongRequest{}, NewInternalError("could not save new request", err)
 	}
+	newSongRequest.TrackMetadata = trackMetadata
 
 	session.UpdatedAt = time.Now()
 	tx.Save(session.SimpleListeningSession)
@@ -319,68 +392,138 @@ func createNewSongRequestInTransaction(session model.FullListeningSession, track
 	return newSongRequest, nil
 }
 
-func getRequestCountForUser(session model.SimpleListeningSession, requestedBy string, tx *gorm.DB) (int64, error) {
+func lockRequestIfQueueIsShortInTransaction(session model.SimpleListeningSession, request *model.SongRequest, tx *gorm.DB) error {
+
+	filter := map[string]interface{}{
+		"session_id":      session.ID,
+		"played":          false,
+		"approval_status": model.ApprovalStatusApproved,
+	}
+	queueLength, err := FindSongRequestCountInTransaction(filter, tx)
+	if err != nil {
+		return err
+	}
+
+	if queueLength < int64(queueLookahead(session)) {
+		// Locked requests are ordered by their weight, so it has to reflect the position in the queue
+		request.Locked = true
+		request.Weight = queueLength
+	}
+
+	return nil
+}
+
+func getRequestCountForRequester(session model.SimpleListeningSession, guest *model.Guest, tx *gorm.DB) (int64, error) {
+
+	var count int64
+	err := findRequestsOfRequesterInTransaction(session, guest, tx).Count(&count).Error
+	return count, err
+}
+
+// Locked requests are ordered by their position. Once a request is unlocked again, it needs back the weight it got when it was requested.
+func getUnlockedWeightInTransaction(session model.SimpleListeningSession, request model.SongRequest, tx *gorm.DB) (int64, error) {
+
+	var guest *model.Guest
+	if request.GuestId != nil {
+		guest = &model.Guest{BaseModel: model.BaseModel{ID: *request.GuestId}}
+	}
+
+	var count int64
+	err := findRequestsOfRequesterInTransaction(session, guest, tx).Where("id < ?", request.ID).Count(&count).Error
+	return count, err
+}
+
+func findRequestsOfRequesterInTransaction(session model.SimpleListeningSession, guest *model.Guest, tx *gorm.DB) *gorm.DB {
 
 	filter := map[string]interface{}{
 		"session_id":   session.ID,
-		"requested_by": requestedBy,
+		"requested_by": fallbackPlaylistRequester,
 	}
-	return FindSongRequestCountInTransaction(filter, tx)
+	if guest != nil {
+		filter = map[string]interface{}{
+			"session_id": session.ID,
+			"guest_id":   guest.ID,
+		}
+	}
+
+	// Rejected requests never made it into the queue, so they do not count against the requester
+	return tx.Model(model.SongRequest{}).Where(filter).Where("approval_status <> ?", model.ApprovalStatusRejected)
 }
 
 func UpdateSessionIfNecessary(session model.FullListeningSession) *SpotifeteError {
+	_, spotifeteError := updateSession(session)
+	return spotifeteError
+}
+
+// Also returns what Spotify is playing for the session, so the poller knows when the current track ends
+func updateSession(session model.FullListeningSession) (*spotify.CurrentlyPlaying, *SpotifeteError) {
+
+	unlock := lockSession(session.SimpleListeningSession)
+	defer unlock()
 
-	queue, err := GetLimitedQueue(session.SimpleListeningSession, 3)
+	queue, err := getQueueWithLookahead(session.SimpleListeningSession)
 	if err != nil {
-		return NewInternalError("Could not fetch queue from database", err)
+		return nil, NewInternalError("Could not fetch queue from database", err)
 	}
 
 	queue, spotifeteError := addFallbackTrackIfNecessary(session, queue)
 	if spotifeteError != nil {
-		return spotifeteError
+		return nil, spotifeteError
 	}
 
-	if shouldUpdateQueue(session, queue) {
-		queue, err = updateQueue(queue)
+	currentlyPlaying, err := Client(session).PlayerCurrentlyPlaying()
+	if err != nil {
+		NewInternalError("Could not get currently playing track from Spotify.", err)
+		currentlyPlaying = nil
+	}
+
+	if shouldUpdateQueue(session, queue, currentlyPlaying) {
+		playedRequest := queue[0]
+		queue, err = updateQueue(session.SimpleListeningSession, queue)
 		if err != nil {
-			return NewInternalError("could not update session", err)
+			return currentlyPlaying, NewInternalError("could not update session", err)
 		}
 
 		session.UpdatedAt = time.Now()
 		database.GetConnection().Save(session.SimpleListeningSession)
+
+		publish(QueueAdvanced{Session: session, PlayedRequest: playedRequest, Queue: queue})
+	}
+
+	spotifeteError = syncPlayback(session, queue)
+	if spotifeteError != nil {
+		return currentlyPlaying, spotifeteError
 	}
 
-	return updatePlaylistIfNecessary(session, queue)
+	if currentlyPlaying != nil {
+		watchPlayback(session, queue, *currentlyPlaying)
+	}
+
+	return currentlyPlaying, nil
 }
 
-func shouldUpdateQueue(session model.FullListeningSession, queue []model.SongRequest) bool {
+func shouldUpdateQueue(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying *spotify.CurrentlyPlaying) bool {
 
 	if len(queue) < 2 {
 		return false
 	}
 
-	currentlyPlaying, err := Client(session).PlayerCurrentlyPlaying()
-	if err != nil {
-		NewInternalError("Could not get currently playing track from Spotify.", err)
-		currentlyPlaying = nil
-	}
-
 	if currentlyPlaying == nil || currentlyPlaying.Item == nil {
 		return false
 	}
 
 	nextRequest := queue[1]
-	return isSessionPlaying(session, currentlyPlaying.PlaybackContext) && nextRequest.SpotifyTrackId == currentlyPlaying.Item.ID.String()
+	return isPlayingSession(session, queue, *currentlyPlaying) && nextRequest.SpotifyTrackId == currentlyPlaying.Item.ID.String()
 }
 
 func isSessionPlaying(session model.FullListeningSession, playbackContext spotify.PlaybackContext) bool {
 	return playbackContext.Type == "playlist" && strings.HasSuffix(string(playbackContext.URI), session.QueuePlaylistId)
 }
 
-func updateQueue(queue []model.SongRequest) (updatedQueue []model.SongRequest, err error) {
+func updateQueue(session model.SimpleListeningSession, queue []model.SongRequest) (updatedQueue []model.SongRequest, err error) {
 
 	updateSessionTask := func(tx *gorm.DB) error {
-		updatedQueue, err = updateQueueInTransaction(queue, tx)
+		updatedQueue, err = updateQueueInTransaction(session, queue, tx)
 		return err
 	}
 	err = database.GetConnection().Transaction(updateSessionTask)
@@ -388,19 +531,14 @@ func updateQueue(queue []model.SongRequest) (updatedQueue []model.SongRequest, e
 	return updatedQueue, err
 }
 
-func updateQueueInTransaction(queue []model.SongRequest, tx *gorm.DB) (updatedQueue []model.SongRequest, err error) {
+func updateQueueInTransaction(session model.SimpleListeningSession, queue []model.SongRequest, tx *gorm.DB) (updatedQueue []model.SongRequest, err error) {
 
 	err = markPreviousAsPlayed(queue, tx)
 	if err != nil {
 		return []model.SongRequest{}, err
 	}
 
-	err = updateFirst(queue, tx)
-	if err != nil {
-		return []model.SongRequest{}, err
-	}
-
-	err = updateSecondIfPresent(queue, tx)
+	err = lockUpcomingRequests(session, queue, tx)
 	if err != nil {
 		return []model.SongRequest{}, err
 	}
@@ -410,26 +548,28 @@ func updateQueueInTransaction(queue []model.SongRequest, tx *gorm.DB) (updatedQu
 
 func markPreviousAsPlayed(queue []model.SongRequest, tx *gorm.DB) error {
 
+	now := time.Now()
 	queue[0].Played = true
+	queue[0].PlayedAt = &now
 	return tx.Save(&queue[0]).Error
 }
 
-func updateFirst(queue []model.SongRequest, tx *gorm.DB) error {
-
-	queue[1].Locked = true
-	queue[1].Weight = 0
-	return tx.Save(&queue[1]).Error
-}
+// Once the first request has been played, the lookahead moves one request further into the queue
+func lockUpcomingRequests(session model.SimpleListeningSession, queue []model.SongRequest, tx *gorm.DB) error {
 
-func updateSecondIfPresent(queue []model.SongRequest, tx *gorm.DB) error {
+	lookahead := queueLookahead(session)
+	for position := 1; position <= lookahead && position < len(queue); position++ {
+		queue[position].Locked = true
+		queue[position].Weight = int64(position - 1)
+		queue[position].ManualPosition = nil
 
-	if len(queue) >= 3 {
-		queue[2].Locked = true
-		queue[2].Weight = 1
-		return tx.Save(&queue[2]).Error
-	} else {
-		return nil
+		err := tx.Save(&queue[position]).Error
+		if err != nil {
+			return err
+		}
 	}
+
+	return repinRequestsInTransaction(queue[1:], tx)
 }
 
 func updatePlaylistIfNecessary(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError {
@@ -448,8 +588,8 @@ func updatePlaylistIfNecessary(session model.FullListeningSession, queue []model
 
 func shouldUpdatePlaylist(session model.FullListeningSession, queue []model.SongRequest) (bool, *SpotifeteError) {
 
-	queueLength := len(queue)
-	if queueLength == 0 {
+	playlistQueue := getPlaylistQueue(session, queue)
+	if len(playlistQueue) == 0 {
 		return false, nil
 	}
 
@@ -461,22 +601,12 @@ func shouldUpdatePlaylist(session model.FullListeningSession, queue []model.Song
 	}
 
 	playlistTracks := playlist.Tracks.Tracks
-	playlistLength := len(playlistTracks)
-
-	if playlistLength <= 0 {
-		return true, nil
-	}
-
-	if queue[0].SpotifyTrackId != playlistTracks[0].Track.ID.String() {
+	if len(playlistTracks) != len(playlistQueue) {
 		return true, nil
 	}
 
-	if queueLength > 1 {
-		if playlistLength <= 1 {
-			return true, nil
-		}
-
-		if queue[1].SpotifyTrackId != playlistTracks[1].Track.ID.String() {
+	for i, request := range playlistQueue {
+		if request.SpotifyTrackId != playlistTracks[i].Track.ID.String() {
 			return true, nil
 		}
 	}
@@ -486,18 +616,12 @@ func shouldUpdatePlaylist(session model.FullListeningSession, queue []model.Song
 
 func updatePlaylist(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError {
 
-	client := Client(session)
-	playlistId := spotify.ID(session.QueuePlaylistId)
-	firstTrackId := spotify.ID(queue[0].SpotifyTrackId)
-
-	var err error
-	if len(queue) > 1 {
-		secondTrackId := spotify.ID(queue[1].SpotifyTrackId)
-		err = client.ReplacePlaylistTracks(playlistId, firstTrackId, secondTrackId)
-	} else {
-		err = client.ReplacePlaylistTracks(playlistId, firstTrackId)
+	var trackIds []spotify.ID
+	for _, request := range getPlaylistQueue(session, queue) {
+		trackIds = append(trackIds, spotify.ID(request.SpotifyTrackId))
 	}
 
+	err := Client(session).ReplacePlaylistTracks(spotify.ID(session.QueuePlaylistId), trackIds...)
 	if err != nil {
 		return NewError("Could not update tracks in playlist.", err, http.StatusInternalServerError)
 	}
@@ -505,6 +629,17 @@ func updatePlaylist(session model.FullListeningSession, queue []model.SongReques
 	return nil
 }
 
+// Only the requests within the lookahead are put into the playlist
+func getPlaylistQueue(session model.FullListeningSession, queue []model.SongRequest) []model.SongRequest {
+
+	lookahead := queueLookahead(session.SimpleListeningSession)
+	if len(queue) > lookahead {
+		return queue[:lookahead]
+	}
+
+	return queue
+}
+
 func NewQueuePlaylist(session model.FullListeningSession) *SpotifeteError {
 
 	owner := session.Owner
@@ -520,8 +655,20 @@ func NewQueuePlaylist(session model.FullListeningSession) *SpotifeteError {
 		return spotifeteError
 	}
 
-	session.QueuePlaylistId = newPlaylist.ID.String()
-	database.GetConnection().Save(&session)
+	newQueuePlaylistTask := func(tx *gorm.DB) error {
+		session.QueuePlaylistId = newPlaylist.ID.String()
+		err := tx.Save(&session).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueSetQueuePlaylistImageInTransaction(owner, newPlaylist.ID, session.JoinId, tx)
+	}
+
+	err = database.GetConnection().Transaction(newQueuePlaylistTask)
+	if err != nil {
+		return NewInternalError("could not save new queue playlist", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,130 @@
+package listeningSession
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/logger"
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+const (
+	EventRequestCreated          = "REQUEST_CREATED"
+	EventRequestDeleted          = "REQUEST_DELETED"
+	EventRequestVoted            = "REQUEST_VOTED"
+	EventRequestLocked           = "REQUEST_LOCKED"
+	EventRequestApproved         = "REQUEST_APPROVED"
+	EventRequestRejected         = "REQUEST_REJECTED"
+	EventQueueReordered          = "QUEUE_REORDERED"
+	EventQueueAdvanced           = "QUEUE_ADVANCED"
+	EventFallbackPlaylistChanged = "FALLBACK_PLAYLIST_CHANGED"
+	EventSessionClosed           = "SESSION_CLOSED"
+)
+
+// Subscribers that do not keep up with the events of a session miss some of them instead of blocking everybody else
+const sessionEventBufferSize = 32
+
+type SessionEvent struct {
+	Type           string    `json:"type"`
+	SpotifyTrackId string    `json:"spotify_track_id,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+var sessionEventSubscribers = struct {
+	sync.Mutex
+	channels map[uint]map[chan SessionEvent]bool
+}{
+	channels: map[uint]map[chan SessionEvent]bool{},
+}
+
+// The returned function has to be called once the subscriber is done, otherwise the channel is kept forever
+func SubscribeToSessionEvents(session model.SimpleListeningSession) (events <-chan SessionEvent, unsubscribe func()) {
+
+	channel := make(chan SessionEvent, sessionEventBufferSize)
+
+	sessionEventSubscribers.Lock()
+	defer sessionEventSubscribers.Unlock()
+
+	if sessionEventSubscribers.channels[session.ID] == nil {
+		sessionEventSubscribers.channels[session.ID] = map[chan SessionEvent]bool{}
+	}
+	sessionEventSubscribers.channels[session.ID][channel] = true
+
+	unsubscribe = func() {
+		sessionEventSubscribers.Lock()
+		defer sessionEventSubscribers.Unlock()
+
+		delete(sessionEventSubscribers.channels[session.ID], channel)
+		if len(sessionEventSubscribers.channels[session.ID]) == 0 {
+			delete(sessionEventSubscribers.channels, session.ID)
+		}
+	}
+
+	return channel, unsubscribe
+}
+
+func publishSessionEvent(session model.SimpleListeningSession, eventType string, spotifyTrackId string) {
+
+	event := SessionEvent{
+		Type:           eventType,
+		SpotifyTrackId: spotifyTrackId,
+		Time:           time.Now(),
+	}
+
+	sessionEventSubscribers.Lock()
+	defer sessionEventSubscribers.Unlock()
+
+	for channel := range sessionEventSubscribers.channels[session.ID] {
+		select {
+		case channel <- event:
+		default:
+			logger.Warningf("Dropped %s event for session %d because a subscriber is not keeping up.", eventType, session.ID)
+		}
+	}
+}
+
+// Forwards the domain events that change what guests see to the live update subscribers
+func subscribeLiveUpdates() {
+	Subscribe(func(event SongRequested) {
+		publishSongRequested(event.Session.SimpleListeningSession, event.Request, event.UpvotedDuplicate)
+	})
+	Subscribe(func(event RequestDeleted) {
+		publishSessionEvent(event.Session, EventRequestDeleted, event.Request.SpotifyTrackId)
+	})
+	Subscribe(func(event QueueAdvanced) {
+		publishQueueAdvanced(event.Session.SimpleListeningSession, event.Queue)
+	})
+	Subscribe(func(event SessionClosed) {
+		publishSessionEvent(event.Session.SimpleListeningSession, EventSessionClosed, "")
+	})
+}
+
+func publishSongRequested(session model.SimpleListeningSession, request model.SongRequest, upvotedDuplicate bool) {
+
+	if upvotedDuplicate {
+		publishSessionEvent(session, EventRequestVoted, request.SpotifyTrackId)
+		return
+	}
+
+	publishSessionEvent(session, EventRequestCreated, request.SpotifyTrackId)
+	if request.Locked {
+		publishSessionEvent(session, EventRequestLocked, request.SpotifyTrackId)
+	}
+}
+
+// Publishes the events for a queue that just moved on to its next request
+func publishQueueAdvanced(session model.SimpleListeningSession, updatedQueue []model.SongRequest) {
+
+	if len(updatedQueue) == 0 {
+		publishSessionEvent(session, EventQueueAdvanced, "")
+		return
+	}
+
+	publishSessionEvent(session, EventQueueAdvanced, updatedQueue[0].SpotifyTrackId)
+
+	// The queue was fetched with one request more than the lookahead, so the last one has just been locked
+	lookahead := queueLookahead(session)
+	if len(updatedQueue) == lookahead {
+		publishSessionEvent(session, EventRequestLocked, updatedQueue[lookahead-1].SpotifyTrackId)
+	}
+}
@@ -0,0 +1,52 @@
+package listeningSession
+
+import (
+	"strings"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+// The country tracks have to be available in to be requested. Search results, track lookups and playlists are loaded for this market.
+// Empty if neither the session nor the profile of the owner has one.
+func Market(session model.FullListeningSession) string {
+	if session.Market != nil {
+		return *session.Market
+	}
+
+	return session.Owner.Country
+}
+
+// Spotify rejects an empty market, so it is left out of the request instead
+func marketOption(market string) *string {
+	if market == "" {
+		return nil
+	}
+
+	return &market
+}
+
+// An empty market resets the session to the country of the owner
+func SetMarket(session model.SimpleListeningSession, user model.SimpleUser, market string) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the market.")
+	}
+
+	var newMarket *string
+	cleanedMarket := strings.ToUpper(strings.TrimSpace(market))
+	if cleanedMarket != "" {
+		if !countryCodes[cleanedMarket] {
+			return NewUserError("The market must be a two letter country code like DE or US.")
+		}
+
+		newMarket = &cleanedMarket
+	}
+
+	err := database.GetConnection().Model(&session).Update("market", newMarket).Error
+	if err != nil {
+		return NewInternalError("could not change market", err)
+	}
+
+	return nil
+}
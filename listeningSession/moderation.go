@@ -0,0 +1,194 @@
+package listeningSession
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+func SetModerated(session model.FullListeningSession, user model.SimpleUser, moderated bool) (spotifeteError *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the moderation mode.")
+	}
+
+	if moderated == session.Moderated {
+		return nil
+	}
+
+	var approvedRequests []model.SongRequest
+	setModeratedTask := func(tx *gorm.DB) error {
+		session.Moderated = moderated
+		err := tx.Model(&session.SimpleListeningSession).Update("moderated", moderated).Error
+		if err != nil {
+			return err
+		}
+
+		if moderated {
+			return nil
+		}
+
+		// Without moderation nobody would ever approve the pending requests, so they are let into the queue right away
+		approvedRequests, err = findPendingRequestsInTransaction(session.SimpleListeningSession, tx)
+		if err != nil {
+			return err
+		}
+
+		for i := range approvedRequests {
+			err = approveRequestInTransaction(session.SimpleListeningSession, &approvedRequests[i], tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := database.GetConnection().Transaction(setModeratedTask)
+	if err != nil {
+		return NewInternalError("could not change moderation mode", err)
+	}
+
+	if !moderated {
+		for _, approvedRequest := range approvedRequests {
+			publish(RequestApproved{Session: session, Request: approvedRequest})
+		}
+		publish(QueueChanged{Session: session})
+		publishSessionEvent(session.SimpleListeningSession, EventQueueReordered, "")
+	}
+
+	return nil
+}
+
+func GetPendingRequests(session model.SimpleListeningSession, user model.SimpleUser) ([]model.SongRequest, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return nil, NewUserError("Only the session owner can see pending requests.")
+	}
+
+	pendingRequests, err := findPendingRequestsInTransaction(session, database.GetConnection())
+	if err != nil {
+		return nil, NewInternalError("Could not fetch pending requests from database", err)
+	}
+
+	return pendingRequests, nil
+}
+
+func findPendingRequestsInTransaction(session model.SimpleListeningSession, tx *gorm.DB) ([]model.SongRequest, error) {
+
+	filter := map[string]interface{}{
+		"session_id":      session.ID,
+		"played":          false,
+		"approval_status": model.ApprovalStatusPending,
+	}
+	query := tx.Where(filter).Order("song_requests.created_at asc")
+
+	return FindSongRequests(query)
+}
+
+func ApproveRequest(session model.FullListeningSession, user model.SimpleUser, spotifyTrackId string) (spotifeteError *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can approve requests.")
+	}
+
+	var approvedRequest *model.SongRequest
+	approveTask := func(tx *gorm.DB) error {
+		approvedRequest, spotifeteError = findPendingRequestForTrackInTransaction(session.SimpleListeningSession, spotifyTrackId, tx)
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		return approveRequestInTransaction(session.SimpleListeningSession, approvedRequest, tx)
+	}
+
+	err := database.GetConnection().Transaction(approveTask)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if err != nil {
+		return NewInternalError("could not approve request", err)
+	}
+
+	publish(RequestApproved{Session: session, Request: *approvedRequest})
+	publish(QueueChanged{Session: session})
+	publishSessionEvent(session.SimpleListeningSession, EventRequestApproved, spotifyTrackId)
+
+	return nil
+}
+
+func approveRequestInTransaction(session model.SimpleListeningSession, request *model.SongRequest, tx *gorm.DB) error {
+
+	err := lockRequestIfQueueIsShortInTransaction(session, request, tx)
+	if err != nil {
+		return err
+	}
+
+	request.ApprovalStatus = model.ApprovalStatusApproved
+	return tx.Model(request).Updates(map[string]interface{}{
+		"approval_status": model.ApprovalStatusApproved,
+		"locked":          request.Locked,
+		"weight":          request.Weight,
+	}).Error
+}
+
+func RejectRequest(session model.SimpleListeningSession, user model.SimpleUser, spotifyTrackId string, reason string) (spotifeteError *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can reject requests.")
+	}
+
+	var rejectionReason *string
+	trimmedReason := strings.TrimSpace(reason)
+	if len(trimmedReason) > 255 {
+		return NewUserError("Rejection reason must not be longer than 255 characters.")
+	}
+	if len(trimmedReason) > 0 {
+		rejectionReason = &trimmedReason
+	}
+
+	rejectTask := func(tx *gorm.DB) error {
+		var pendingRequest *model.SongRequest
+		pendingRequest, spotifeteError = findPendingRequestForTrackInTransaction(session, spotifyTrackId, tx)
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		return tx.Model(pendingRequest).Updates(map[string]interface{}{
+			"approval_status":  model.ApprovalStatusRejected,
+			"rejection_reason": rejectionReason,
+		}).Error
+	}
+
+	err := database.GetConnection().Transaction(rejectTask)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if err != nil {
+		return NewInternalError("could not reject request", err)
+	}
+
+	publishSessionEvent(session, EventRequestRejected, spotifyTrackId)
+
+	return nil
+}
+
+func findPendingRequestForTrackInTransaction(session model.SimpleListeningSession, spotifyTrackId string, tx *gorm.DB) (*model.SongRequest, *SpotifeteError) {
+
+	query := tx.Where(map[string]interface{}{
+		"song_requests.session_id":       session.ID,
+		"song_requests.spotify_track_id": spotifyTrackId,
+		"song_requests.played":           false,
+		"song_requests.approval_status":  model.ApprovalStatusPending,
+	})
+	pendingRequests, err := FindSongRequests(query)
+	if err != nil {
+		return nil, NewInternalError("Could not fetch pending request from database", err)
+	}
+
+	if len(pendingRequests) == 0 {
+		return nil, NewUserError("Request not found in pending requests.")
+	}
+
+	return &pendingRequests[0], nil
+}
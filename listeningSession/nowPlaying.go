@@ -0,0 +1,91 @@
+package listeningSession
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/patrickmn/go-cache"
+)
+
+// Guests and the queue estimates ask for this a lot, so Spotify is asked at most once every few seconds per session
+var nowPlayingCache = cache.New(5*time.Second, time.Minute)
+
+type NowPlaying struct {
+	// False if nothing is playing or playback is paused
+	IsPlaying bool `json:"is_playing"`
+	// Whether the owner's player is actually playing the queue of the session and not something else
+	SessionIsActiveContext bool                 `json:"session_is_active_context"`
+	Track                  *model.TrackMetadata `json:"track"`
+	ProgressMs             int                  `json:"progress_ms"`
+	DurationMs             int                  `json:"duration_ms"`
+	// When Spotify reported the progress
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func GetNowPlaying(session model.FullListeningSession) (NowPlaying, *SpotifeteError) {
+
+	cacheKey := strconv.FormatUint(uint64(session.ID), 10)
+	cachedNowPlaying, found := nowPlayingCache.Get(cacheKey)
+	if found {
+		return cachedNowPlaying.(NowPlaying), nil
+	}
+
+	currentlyPlaying, err := Client(session).PlayerCurrentlyPlaying()
+	if err != nil {
+		return NowPlaying{}, NewError("Could not get currently playing track from Spotify.", err, http.StatusInternalServerError)
+	}
+
+	nowPlaying := NowPlaying{
+		IsPlaying: currentlyPlaying.Playing,
+		Timestamp: time.Now(),
+	}
+
+	if currentlyPlaying.Item != nil {
+		track := model.TrackMetadata{}.SetMetadata(*currentlyPlaying.Item)
+		nowPlaying.Track = &track
+		nowPlaying.ProgressMs = currentlyPlaying.Progress
+		nowPlaying.DurationMs = currentlyPlaying.Item.Duration
+
+		queue, err := getQueueWithLookahead(session.SimpleListeningSession)
+		if err != nil {
+			return NowPlaying{}, NewInternalError("Could not fetch queue from database", err)
+		}
+		nowPlaying.SessionIsActiveContext = isPlayingSession(session, queue, *currentlyPlaying)
+	}
+
+	nowPlayingCache.SetDefault(cacheKey, nowPlaying)
+	return nowPlaying, nil
+}
+
+// Estimates when each request of the queue starts playing, based on the stored track durations.
+// If the first request is playing right now, its progress is taken into account. Otherwise it is assumed to start right away.
+// Requests behind a track with unknown duration get no estimate.
+func EstimateStartTimes(queue []model.SongRequest, nowPlaying *NowPlaying) []*time.Time {
+
+	estimatedStartTimes := make([]*time.Time, len(queue))
+	if len(queue) == 0 {
+		return estimatedStartTimes
+	}
+
+	startTime := time.Now()
+	if nowPlaying != nil && nowPlaying.SessionIsActiveContext && nowPlaying.Track != nil && nowPlaying.Track.SpotifyTrackId == queue[0].SpotifyTrackId {
+		startTime = nowPlaying.Timestamp.Add(-time.Duration(nowPlaying.ProgressMs) * time.Millisecond)
+	}
+
+	for i, request := range queue {
+		estimatedStartTime := startTime
+		estimatedStartTimes[i] = &estimatedStartTime
+
+		duration := request.TrackMetadata.Duration()
+		if duration <= 0 {
+			break
+		}
+
+		startTime = startTime.Add(duration)
+	}
+
+	return estimatedStartTimes
+}
@@ -0,0 +1,114 @@
+package listeningSession
+
+import (
+	"testing"
+	"time"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+func queuedRequest(trackId string, durationMs int) model.SongRequest {
+	return model.SongRequest{
+		SpotifyTrackId: trackId,
+		TrackMetadata: model.TrackMetadata{
+			SpotifyTrackId: trackId,
+			DurationMs:     durationMs,
+		},
+	}
+}
+
+func TestEstimateStartTimesOfEmptyQueue(t *testing.T) {
+
+	if startTimes := EstimateStartTimes(nil, nil); len(startTimes) != 0 {
+		t.Errorf("got %d start times, want none", len(startTimes))
+	}
+}
+
+func TestEstimateStartTimesAddsUpDurations(t *testing.T) {
+
+	queue := []model.SongRequest{
+		queuedRequest("track1", 180000),
+		queuedRequest("track2", 200000),
+		queuedRequest("track3", 240000),
+	}
+
+	before := time.Now()
+	startTimes := EstimateStartTimes(queue, nil)
+
+	if len(startTimes) != len(queue) {
+		t.Fatalf("got %d start times, want %d", len(startTimes), len(queue))
+	}
+	if startTimes[0].Before(before) || startTimes[0].After(time.Now()) {
+		t.Errorf("first request starts at %s, want now", startTimes[0])
+	}
+	if gap := startTimes[1].Sub(*startTimes[0]); gap != 180*time.Second {
+		t.Errorf("second request starts %s after the first, want 3m0s", gap)
+	}
+	if gap := startTimes[2].Sub(*startTimes[1]); gap != 200*time.Second {
+		t.Errorf("third request starts %s after the second, want 3m20s", gap)
+	}
+}
+
+func TestEstimateStartTimesTakesProgressIntoAccount(t *testing.T) {
+
+	queue := []model.SongRequest{
+		queuedRequest("track1", 180000),
+		queuedRequest("track2", 200000),
+	}
+	reportedAt := time.Now()
+	nowPlaying := &NowPlaying{
+		IsPlaying:              true,
+		SessionIsActiveContext: true,
+		Track:                  &model.TrackMetadata{SpotifyTrackId: "track1"},
+		ProgressMs:             60000,
+		Timestamp:              reportedAt,
+	}
+
+	startTimes := EstimateStartTimes(queue, nowPlaying)
+
+	if want := reportedAt.Add(-time.Minute); !startTimes[0].Equal(want) {
+		t.Errorf("first request started at %s, want %s", startTimes[0], want)
+	}
+	if want := reportedAt.Add(2 * time.Minute); !startTimes[1].Equal(want) {
+		t.Errorf("second request starts at %s, want %s", startTimes[1], want)
+	}
+}
+
+func TestEstimateStartTimesIgnoresProgressOfOtherTracks(t *testing.T) {
+
+	queue := []model.SongRequest{
+		queuedRequest("track1", 180000),
+	}
+	nowPlaying := &NowPlaying{
+		IsPlaying:              true,
+		SessionIsActiveContext: false,
+		Track:                  &model.TrackMetadata{SpotifyTrackId: "track1"},
+		ProgressMs:             60000,
+		Timestamp:              time.Now(),
+	}
+
+	before := time.Now()
+	startTimes := EstimateStartTimes(queue, nowPlaying)
+
+	if startTimes[0].Before(before) {
+		t.Errorf("first request started at %s, want now because the session is not playing", startTimes[0])
+	}
+}
+
+func TestEstimateStartTimesStopsAtUnknownDuration(t *testing.T) {
+
+	queue := []model.SongRequest{
+		queuedRequest("track1", 180000),
+		queuedRequest("track2", 0),
+		queuedRequest("track3", 240000),
+	}
+
+	startTimes := EstimateStartTimes(queue, nil)
+
+	if startTimes[0] == nil || startTimes[1] == nil {
+		t.Fatalf("requests up to the one with unknown duration have no estimate")
+	}
+	if startTimes[2] != nil {
+		t.Errorf("request behind a track with unknown duration starts at %s, want no estimate", startTimes[2])
+	}
+}
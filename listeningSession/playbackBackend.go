@@ -0,0 +1,246 @@
+package listeningSession
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/zmb3/spotify"
+)
+
+// A PlaybackBackend decides how the locked requests of a session get into the owner's Spotify player.
+type PlaybackBackend interface {
+	Name() string
+	Description() string
+	// Brings the owner's player in line with the locked requests at the start of the queue
+	syncQueue(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError
+	// Starts playing the queue from its first request. Without a device id, Spotify uses the currently active device.
+	play(session model.FullListeningSession, queue []model.SongRequest, device *spotify.ID) *SpotifeteError
+	// Whether Spotify is currently playing the session, regardless of it being paused
+	isPlaying(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) bool
+}
+
+const defaultPlaybackBackend = "PLAYLIST"
+
+var playbackBackends = []PlaybackBackend{
+	playlistPlaybackBackend{},
+	nativeQueuePlaybackBackend{},
+}
+
+func PlaybackBackends() []PlaybackBackend {
+	return playbackBackends
+}
+
+func FindPlaybackBackend(name string) PlaybackBackend {
+	for _, backend := range playbackBackends {
+		if backend.Name() == name {
+			return backend
+		}
+	}
+
+	return nil
+}
+
+func playbackBackendForSession(session model.SimpleListeningSession) PlaybackBackend {
+	backend := FindPlaybackBackend(session.PlaybackBackend)
+	if backend == nil {
+		return FindPlaybackBackend(defaultPlaybackBackend)
+	}
+
+	return backend
+}
+
+func SetPlaybackBackend(session model.SimpleListeningSession, user model.SimpleUser, backendName string) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the playback mode.")
+	}
+
+	if FindPlaybackBackend(backendName) == nil {
+		return NewUserError("Unknown playback mode.")
+	}
+
+	if backendName == session.PlaybackBackend {
+		return nil
+	}
+
+	session.PlaybackBackend = backendName
+	database.GetConnection().Model(&session).Update("playback_backend", backendName)
+
+	return nil
+}
+
+func syncPlayback(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError {
+	return playbackBackendForSession(session.SimpleListeningSession).syncQueue(session, queue)
+}
+
+// The queue is fetched again under the session lock, so a poll that advanced the queue in the meantime is not undone
+func syncPlaybackWithLatestQueue(session model.FullListeningSession) {
+
+	unlock := lockSession(session.SimpleListeningSession)
+	defer unlock()
+
+	queue, err := getQueueWithLookahead(session.SimpleListeningSession)
+	if err != nil {
+		NewInternalError("Could not fetch queue from database", err)
+		return
+	}
+
+	syncPlayback(session, queue)
+}
+
+func isPlayingSession(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) bool {
+	return playbackBackendForSession(session.SimpleListeningSession).isPlaying(session, queue, currentlyPlaying)
+}
+
+// Shuffle and repeat would mess up the order of the queue
+func turnOffShuffleAndRepeat(client spotifyApi.Client, device *spotify.ID) *SpotifeteError {
+
+	err := client.ShuffleOpt(false, &spotify.PlayOptions{DeviceID: device})
+	if err != nil {
+		return NewError("Could not turn off shuffle on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	err = client.RepeatOpt("off", &spotify.PlayOptions{DeviceID: device})
+	if err != nil {
+		return NewError("Could not turn off repeat on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// Keeps the locked requests in a playlist that the owner has to play
+type playlistPlaybackBackend struct{}
+
+func (playlistPlaybackBackend) Name() string {
+	return "PLAYLIST"
+}
+
+func (playlistPlaybackBackend) Description() string {
+	return "Session playlist (play the SpotiFete playlist in Spotify)"
+}
+
+func (playlistPlaybackBackend) syncQueue(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError {
+	return updatePlaylistIfNecessary(session, queue)
+}
+
+func (playlistPlaybackBackend) play(session model.FullListeningSession, queue []model.SongRequest, device *spotify.ID) *SpotifeteError {
+
+	spotifeteError := updatePlaylistIfNecessary(session, queue)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	client := Client(session)
+	playlistUri := spotify.URI(fmt.Sprintf("spotify:playlist:%s", session.QueuePlaylistId))
+	err := client.PlayOpt(&spotify.PlayOptions{
+		DeviceID:        device,
+		PlaybackContext: &playlistUri,
+		PlaybackOffset: &spotify.PlaybackOffset{
+			URI: spotify.URI(fmt.Sprintf("spotify:track:%s", queue[0].SpotifyTrackId)),
+		},
+	})
+	if err != nil {
+		return NewError("Could not start playback on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return turnOffShuffleAndRepeat(client, device)
+}
+
+func (playlistPlaybackBackend) isPlaying(session model.FullListeningSession, _ []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) bool {
+	return isSessionPlaying(session, currentlyPlaying.PlaybackContext)
+}
+
+// Adds the locked requests to the owner's Spotify queue, so they can keep using Spotify as usual.
+// Spotify does not allow to remove tracks from the queue, so every request is only added once.
+type nativeQueuePlaybackBackend struct{}
+
+func (nativeQueuePlaybackBackend) Name() string {
+	return "NATIVE_QUEUE"
+}
+
+func (nativeQueuePlaybackBackend) Description() string {
+	return "Spotify queue (requests are added to your Spotify queue, they can not be removed again)"
+}
+
+func (nativeQueuePlaybackBackend) syncQueue(session model.FullListeningSession, queue []model.SongRequest) *SpotifeteError {
+
+	client := Client(session)
+	for _, request := range queue {
+		if !request.Locked || request.PushedToPlayer {
+			continue
+		}
+
+		claimed, err := claimRequestForPlayer(request)
+		if err != nil {
+			return NewInternalError("Could not mark request as added to Spotify queue", err)
+		}
+		if !claimed {
+			continue
+		}
+
+		err = client.QueueSong(spotify.ID(request.SpotifyTrackId))
+		if err != nil {
+			unclaimRequestForPlayer(request)
+			return NewError("Could not add track to Spotify queue.", err, http.StatusInternalServerError)
+		}
+	}
+
+	return nil
+}
+
+func (b nativeQueuePlaybackBackend) play(session model.FullListeningSession, queue []model.SongRequest, device *spotify.ID) *SpotifeteError {
+
+	client := Client(session)
+	err := client.PlayOpt(&spotify.PlayOptions{
+		DeviceID: device,
+		URIs:     []spotify.URI{spotify.URI(fmt.Sprintf("spotify:track:%s", queue[0].SpotifyTrackId))},
+	})
+	if err != nil {
+		return NewError("Could not start playback on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	_, err = claimRequestForPlayer(queue[0])
+	if err != nil {
+		return NewInternalError("Could not mark request as added to Spotify queue", err)
+	}
+	queue[0].PushedToPlayer = true
+
+	spotifeteError := turnOffShuffleAndRepeat(client, device)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	return b.syncQueue(session, queue)
+}
+
+func (nativeQueuePlaybackBackend) isPlaying(_ model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) bool {
+
+	if currentlyPlaying.Item == nil {
+		return false
+	}
+
+	return queueContainsTrack(queue, currentlyPlaying.Item.ID.String())
+}
+
+// Makes sure a request is only added to the Spotify queue once, even if the poller and a request sync at the same time
+func claimRequestForPlayer(request model.SongRequest) (claimed bool, err error) {
+
+	result := database.GetConnection().Model(&model.SongRequest{}).
+		Where("id = ? AND pushed_to_player = false", request.ID).
+		UpdateColumn("pushed_to_player", true)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func unclaimRequestForPlayer(request model.SongRequest) {
+
+	err := database.GetConnection().Model(&model.SongRequest{}).
+		Where("id = ?", request.ID).
+		UpdateColumn("pushed_to_player", false).Error
+	if err != nil {
+		NewInternalError("could not reset request after failing to add it to Spotify queue", err)
+	}
+}
@@ -0,0 +1,189 @@
+package listeningSession
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/zmb3/spotify"
+	"gorm.io/gorm"
+)
+
+func GetPlaybackDevices(session model.FullListeningSession, user model.SimpleUser) ([]spotify.PlayerDevice, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return nil, NewUserError("Only the session owner can see the playback devices.")
+	}
+
+	devices, err := Client(session).PlayerDevices()
+	if err != nil {
+		return nil, NewError("Could not get playback devices from Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return devices, nil
+}
+
+// Plays the queue from its first request on the given device
+func StartPlayback(session model.FullListeningSession, user model.SimpleUser, deviceId string) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can start playback.")
+	}
+
+	unlock := lockSession(session.SimpleListeningSession)
+	defer unlock()
+
+	queue, err := getQueueWithLookahead(session.SimpleListeningSession)
+	if err != nil {
+		return NewInternalError("Could not fetch queue from database", err)
+	}
+
+	queue, spotifeteError := addFallbackTrackIfNecessary(session, queue)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	if len(queue) == 0 {
+		return NewUserError("Request a track or choose a fallback playlist before starting playback.")
+	}
+
+	device := spotify.ID(deviceId)
+	return playbackBackendForSession(session.SimpleListeningSession).play(session, queue, &device)
+}
+
+// Skips the currently playing request and marks it as played
+func SkipTrack(session model.FullListeningSession, user model.SimpleUser) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can skip tracks.")
+	}
+
+	unlock := lockSession(session.SimpleListeningSession)
+	defer unlock()
+
+	queue, err := getQueueWithLookahead(session.SimpleListeningSession)
+	if err != nil {
+		return NewInternalError("Could not fetch queue from database", err)
+	}
+
+	queue, spotifeteError := addFallbackTrackIfNecessary(session, queue)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	if len(queue) == 0 {
+		return NewUserError("The queue is empty.")
+	}
+
+	client := Client(session)
+	currentlyPlaying, err := client.PlayerCurrentlyPlaying()
+	if err != nil {
+		return NewError("Could not get currently playing track from Spotify.", err, http.StatusInternalServerError)
+	}
+
+	// If something else is playing, only the queue is advanced so the host does not skip their own music
+	if currentlyPlaying != nil && isPlayingSession(session, queue, *currentlyPlaying) {
+		err = client.Next()
+		if err != nil {
+			return NewError("Could not skip track on Spotify.", err, http.StatusInternalServerError)
+		}
+	}
+
+	queue, spotifeteError = advanceQueue(session, queue)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	if len(queue) == 0 {
+		// Syncing the playback leaves the queue playlist alone if nothing is queued, so the skipped track has to be removed here
+		return updatePlaylist(session, queue)
+	}
+
+	return syncPlayback(session, queue)
+}
+
+// Marks the first request as played, even if nothing follows it, and returns the rest of the queue
+func advanceQueue(session model.FullListeningSession, queue []model.SongRequest) ([]model.SongRequest, *SpotifeteError) {
+
+	playedRequest := queue[0]
+	advanceTask := func(tx *gorm.DB) (err error) {
+		if len(queue) < 2 {
+			err = markPreviousAsPlayed(queue, tx)
+			queue = []model.SongRequest{}
+			return err
+		}
+
+		queue, err = updateQueueInTransaction(session.SimpleListeningSession, queue, tx)
+		return err
+	}
+	err := database.GetConnection().Transaction(advanceTask)
+	if err != nil {
+		return nil, NewInternalError("Could not advance queue", err)
+	}
+
+	session.UpdatedAt = time.Now()
+	database.GetConnection().Save(session.SimpleListeningSession)
+
+	publish(QueueAdvanced{Session: session, PlayedRequest: playedRequest, Queue: queue})
+
+	return queue, nil
+}
+
+func PausePlayback(session model.FullListeningSession, user model.SimpleUser) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can pause playback.")
+	}
+
+	err := Client(session).Pause()
+	if err != nil {
+		return NewError("Could not pause playback on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func ResumePlayback(session model.FullListeningSession, user model.SimpleUser) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can resume playback.")
+	}
+
+	err := Client(session).Play()
+	if err != nil {
+		return NewError("Could not resume playback on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func SeekPlayback(session model.FullListeningSession, user model.SimpleUser, positionMs int) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can seek.")
+	}
+
+	if positionMs < 0 {
+		return NewUserError("Position must not be negative.")
+	}
+
+	err := Client(session).Seek(positionMs)
+	if err != nil {
+		return NewError("Could not seek on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func SetVolume(session model.FullListeningSession, user model.SimpleUser, volumePercent int) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the volume.")
+	}
+
+	if volumePercent < 0 || volumePercent > 100 {
+		return NewUserError("Volume must be between 0 and 100.")
+	}
+
+	err := Client(session).Volume(volumePercent)
+	if err != nil {
+		return NewError("Could not change volume on Spotify.", err, http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -55,11 +55,10 @@ func createPlaylistForSession(joinId string, sessionTitle string, user model.Sim
 		return nil, NewError("Could not create spotify playlist.", err, http.StatusInternalServerError)
 	}
 
-	go setPlaylistImage(playlist, joinId, user)
 	return playlist, nil
 }
 
-func setPlaylistImage(playlist *spotify.FullPlaylist, joinId string, user model.SimpleUser) *SpotifeteError {
+func setPlaylistImage(playlistId spotify.ID, joinId string, user model.SimpleUser) *SpotifeteError {
 
 	client := users.Client(user)
 
@@ -68,7 +67,7 @@ func setPlaylistImage(playlist *spotify.FullPlaylist, joinId string, user model.
 		return spotifeteError
 	}
 
-	err := client.SetPlaylistImage(playlist.ID, qrCode)
+	err := client.SetPlaylistImage(playlistId, qrCode)
 	if err == nil {
 		return nil
 	} else {
@@ -76,13 +75,15 @@ func setPlaylistImage(playlist *spotify.FullPlaylist, joinId string, user model.
 	}
 }
 
-func getPlayablePlaylistTracks(playlistId string, user model.SimpleUser) (*[]spotify.FullTrack, *SpotifeteError) {
-	cachedTracks, found := playlistTrackCache.Get(playlistId)
+// Whether a track is playable depends on the market, so the tracks are cached per market
+func getPlayablePlaylistTracks(playlistId string, user model.SimpleUser, market string) (*[]spotify.FullTrack, *SpotifeteError) {
+	cacheKey := playlistId + ":" + market
+	cachedTracks, found := playlistTrackCache.Get(cacheKey)
 	if found {
 		return cachedTracks.(*[]spotify.FullTrack), nil
 	}
 
-	loadedTracks, spotifeteError := loadPlaylistTracksFromSpotify(playlistId, user)
+	loadedTracks, spotifeteError := loadPlaylistTracksFromSpotify(playlistId, user, market)
 	if spotifeteError != nil {
 		return nil, spotifeteError
 	}
@@ -96,15 +97,15 @@ func getPlayablePlaylistTracks(playlistId string, user model.SimpleUser) (*[]spo
 		}
 	}
 
-	playlistTrackCache.SetDefault(playlistId, &playableTracks)
+	playlistTrackCache.SetDefault(cacheKey, &playableTracks)
 	return &playableTracks, nil
 }
 
-func loadPlaylistTracksFromSpotify(playlistId string, user model.SimpleUser) ([]spotify.PlaylistTrack, *SpotifeteError) {
+func loadPlaylistTracksFromSpotify(playlistId string, user model.SimpleUser, market string) ([]spotify.PlaylistTrack, *SpotifeteError) {
 	client := users.Client(user)
 
 	spotifyPlaylistId := spotify.ID(playlistId)
-	searchOptions := spotify.Options{Country: &user.Country}
+	searchOptions := spotify.Options{Country: marketOption(market)}
 	var tracks []spotify.PlaylistTrack
 
 	var tracksLeftToLoad = true
@@ -1,44 +1,195 @@
 package listeningSession
 
 import (
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/partyoffice/spotifete/config"
+	"github.com/partyoffice/spotifete/database"
 	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/zmb3/spotify"
 )
 
+const (
+	// How often the scheduler checks whether a session is due
+	schedulerTick = time.Second
+	// Spotify needs a moment to switch to the next track, so sessions are polled shortly after a track should have ended
+	trackEndMargin = time.Second
+)
+
+type scheduledSession struct {
+	nextPoll time.Time
+	// The last update of the session the scheduler knows about. A newer update, e.g. a new request, is polled right away.
+	updatedAt time.Time
+	polling   bool
+}
+
+// Polls every active session on its own schedule. A session is never polled by more than one worker at once and
+// at most MaxWorkers sessions are polled at the same time.
+type sessionScheduler struct {
+	mutex       sync.Mutex
+	sessions    map[uint]*scheduledSession
+	workerSlots chan bool
+	lastRefresh time.Time
+}
+
 func StartPollSessionsLoop() {
-	go pollSessionsLoop()
+
+	scheduler := &sessionScheduler{
+		sessions:    map[uint]*scheduledSession{},
+		workerSlots: make(chan bool, config.Get().SpotifeteConfiguration.Polling.MaxWorkers),
+	}
+
+	go scheduler.run()
 }
 
-func pollSessionsLoop() {
-	for range time.Tick(5 * time.Second) {
-		go pollSessions()
+func (scheduler *sessionScheduler) run() {
+	for range time.Tick(schedulerTick) {
+		if time.Since(scheduler.lastRefresh) >= config.Get().SpotifeteConfiguration.Polling.Interval {
+			scheduler.refreshSessions()
+		}
+
+		scheduler.pollDueSessions()
 	}
 }
 
-func pollSessions() {
+// Picks up new sessions and forgets closed ones
+func (scheduler *sessionScheduler) refreshSessions() {
+
+	var activeSessions []model.SimpleListeningSession
+	err := database.GetConnection().
+		Select("id", "updated_at").
+		Where(model.SimpleListeningSession{Active: true}).
+		Find(&activeSessions).Error
+	if err != nil {
+		NewInternalError("could not fetch active sessions", err)
+		return
+	}
+	scheduler.lastRefresh = time.Now()
 
-	sessionsToUpdate := findSessionsToUpdate()
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
 
-	for _, session := range sessionsToUpdate {
-		UpdateSessionIfNecessary(session)
+	activeSessionIds := map[uint]bool{}
+	for _, session := range activeSessions {
+		activeSessionIds[session.ID] = true
+
+		scheduled, known := scheduler.sessions[session.ID]
+		if !known {
+			scheduler.sessions[session.ID] = &scheduledSession{
+				nextPoll:  time.Now(),
+				updatedAt: session.UpdatedAt,
+			}
+		} else if session.UpdatedAt.After(scheduled.updatedAt) {
+			scheduled.nextPoll = time.Now()
+			scheduled.updatedAt = session.UpdatedAt
+		}
+	}
+
+	for sessionId, scheduled := range scheduler.sessions {
+		if !activeSessionIds[sessionId] && !scheduled.polling {
+			delete(scheduler.sessions, sessionId)
+		}
 	}
 }
 
-func findSessionsToUpdate() []model.FullListeningSession {
+func (scheduler *sessionScheduler) pollDueSessions() {
 
-	activeSessions := FindFullListeningSessions(model.SimpleListeningSession{
-		Active: true,
-	})
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
 
-	recentlyUpdatedThreshold := time.Now().Add(-1 * time.Hour)
+	now := time.Now()
+	var dueSessionIds []uint
+	for sessionId, scheduled := range scheduler.sessions {
+		if !scheduled.polling && !scheduled.nextPoll.After(now) {
+			dueSessionIds = append(dueSessionIds, sessionId)
+		}
+	}
+
+	// Sessions that have been waiting the longest go first if there are not enough workers for all of them
+	sort.Slice(dueSessionIds, func(i, j int) bool {
+		return scheduler.sessions[dueSessionIds[i]].nextPoll.Before(scheduler.sessions[dueSessionIds[j]].nextPoll)
+	})
 
-	var recentlyUpdatedSessions []model.FullListeningSession
-	for _, s := range activeSessions {
-		if s.UpdatedAt.After(recentlyUpdatedThreshold) {
-			recentlyUpdatedSessions = append(recentlyUpdatedSessions, s)
+	for _, sessionId := range dueSessionIds {
+		select {
+		case scheduler.workerSlots <- true:
+		default:
+			// All workers are busy, the remaining sessions are polled on one of the next ticks
+			return
 		}
+
+		scheduler.sessions[sessionId].polling = true
+		go scheduler.poll(sessionId)
+	}
+}
+
+func (scheduler *sessionScheduler) poll(sessionId uint) {
+
+	defer func() { <-scheduler.workerSlots }()
+
+	nextPollDelay := config.Get().SpotifeteConfiguration.Polling.Interval
+	var updatedAt time.Time
+
+	session := FindFullListeningSession(model.SimpleListeningSession{
+		BaseModel: model.BaseModel{ID: sessionId},
+		Active:    true,
+	})
+	if session != nil {
+		currentlyPlaying, _ := updateSession(*session)
+		nextPollDelay = nextPollDelayForSession(*session, currentlyPlaying)
+		// The update itself may have changed the session, e.g. when the queue advanced. That change must not trigger another poll.
+		updatedAt = findSessionUpdatedAt(session.SimpleListeningSession)
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	scheduled := scheduler.sessions[sessionId]
+	scheduled.polling = false
+	scheduled.nextPoll = time.Now().Add(nextPollDelay)
+	if updatedAt.After(scheduled.updatedAt) {
+		scheduled.updatedAt = updatedAt
+	}
+}
+
+// While a track is playing, nothing changes until it ends, unless a guest requests a track or the owner changes something.
+// These changes update the session, so they are picked up by the next refresh anyway.
+func nextPollDelayForSession(session model.FullListeningSession, currentlyPlaying *spotify.CurrentlyPlaying) time.Duration {
+
+	pollingConfiguration := config.Get().SpotifeteConfiguration.Polling
+
+	if time.Since(session.UpdatedAt) > pollingConfiguration.IdleThreshold {
+		return pollingConfiguration.IdleInterval
+	}
+
+	if currentlyPlaying == nil || currentlyPlaying.Item == nil || !currentlyPlaying.Playing {
+		return pollingConfiguration.Interval
+	}
+
+	remaining := time.Duration(currentlyPlaying.Item.Duration-currentlyPlaying.Progress)*time.Millisecond + trackEndMargin
+	if remaining < trackEndMargin {
+		return trackEndMargin
+	}
+	if remaining > pollingConfiguration.MaxInterval {
+		return pollingConfiguration.MaxInterval
+	}
+
+	return remaining
+}
+
+func findSessionUpdatedAt(session model.SimpleListeningSession) time.Time {
+
+	var updatedSession model.SimpleListeningSession
+	err := database.GetConnection().
+		Select("id", "updated_at").
+		First(&updatedSession, session.ID).Error
+	if err != nil {
+		NewInternalError("could not fetch session", err)
+		return session.UpdatedAt
 	}
 
-	return recentlyUpdatedSessions
+	return updatedSession.UpdatedAt
 }
@@ -0,0 +1,126 @@
+package listeningSession
+
+import (
+	"fmt"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+// The lookahead is the number of requests at the start of the queue that are locked and synced to the owner's player,
+// including the one that is currently playing. Locked requests can not be moved by the queue strategy.
+const (
+	MinQueueLookahead     = 2
+	MaxQueueLookahead     = 10
+	defaultQueueLookahead = MinQueueLookahead
+)
+
+func queueLookahead(session model.SimpleListeningSession) int {
+	if session.QueueLookahead < MinQueueLookahead || session.QueueLookahead > MaxQueueLookahead {
+		return defaultQueueLookahead
+	}
+
+	return int(session.QueueLookahead)
+}
+
+// Fetches the locked requests and the one that will be locked next once the current request has been played
+func getQueueWithLookahead(session model.SimpleListeningSession) ([]model.SongRequest, error) {
+
+	return getQueueWithLookaheadInTransaction(session, database.GetConnection())
+}
+
+func getQueueWithLookaheadInTransaction(session model.SimpleListeningSession, tx *gorm.DB) ([]model.SongRequest, error) {
+
+	return GetLimitedQueueInTransaction(session, queueLookahead(session)+1, tx)
+}
+
+func SetQueueLookahead(session model.FullListeningSession, user model.SimpleUser, lookahead uint) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the queue lookahead.")
+	}
+
+	if lookahead < MinQueueLookahead || lookahead > MaxQueueLookahead {
+		return NewUserError(fmt.Sprintf("The queue lookahead must be between %d and %d tracks.", MinQueueLookahead, MaxQueueLookahead))
+	}
+
+	if lookahead == session.QueueLookahead {
+		return nil
+	}
+
+	setQueueLookaheadTask := func(tx *gorm.DB) error {
+		session.QueueLookahead = lookahead
+		err := tx.Model(&session.SimpleListeningSession).Update("queue_lookahead", lookahead).Error
+		if err != nil {
+			return err
+		}
+
+		return relockQueueInTransaction(session.SimpleListeningSession, tx)
+	}
+
+	err := database.GetConnection().Transaction(setQueueLookaheadTask)
+	if err != nil {
+		return NewInternalError("could not change queue lookahead", err)
+	}
+
+	publish(QueueChanged{Session: session})
+	publishSessionEvent(session.SimpleListeningSession, EventQueueReordered, "")
+
+	return nil
+}
+
+// Locks exactly the requests that are within the lookahead, so changing it takes effect right away.
+// Only requests that were added to the Spotify queue stay locked if the lookahead shrinks.
+func relockQueueInTransaction(session model.SimpleListeningSession, tx *gorm.DB) error {
+
+	queue, err := GetFullQueueInTransaction(session, tx)
+	if err != nil {
+		return err
+	}
+
+	lookahead := queueLookahead(session)
+	for position := range queue {
+		request := &queue[position]
+
+		var updates map[string]interface{}
+		if position < lookahead {
+			if request.Locked && request.Weight == int64(position) {
+				continue
+			}
+
+			request.Locked = true
+			request.Weight = int64(position)
+			request.ManualPosition = nil
+			updates = map[string]interface{}{
+				"locked":          true,
+				"weight":          position,
+				"manual_position": nil,
+			}
+		} else {
+			// Spotify would play requests that were added to its queue anyway
+			if !request.Locked || request.PushedToPlayer {
+				continue
+			}
+
+			weight, err := getUnlockedWeightInTransaction(session, *request, tx)
+			if err != nil {
+				return err
+			}
+
+			request.Locked = false
+			request.Weight = weight
+			updates = map[string]interface{}{
+				"locked": false,
+				"weight": weight,
+			}
+		}
+
+		err = tx.Model(request).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return repinRequestsInTransaction(queue, tx)
+}
@@ -0,0 +1,140 @@
+package listeningSession
+
+import (
+	"fmt"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+// A QueueStrategy decides in which order the unlocked requests of a session are played.
+// Locked requests are always played first, in the order they were locked in.
+type QueueStrategy interface {
+	Name() string
+	Description() string
+	// SQL expression the unlocked requests are ordered by (ascending). Ties are broken by request time.
+	orderExpression() string
+}
+
+const defaultQueueStrategy = "WEIGHTED_FAIRNESS"
+
+var queueStrategies = []QueueStrategy{
+	fifoQueueStrategy{},
+	weightedFairnessQueueStrategy{},
+	roundRobinQueueStrategy{},
+	timeDecayedFairnessQueueStrategy{},
+}
+
+func QueueStrategies() []QueueStrategy {
+	return queueStrategies
+}
+
+func FindQueueStrategy(name string) QueueStrategy {
+	for _, strategy := range queueStrategies {
+		if strategy.Name() == name {
+			return strategy
+		}
+	}
+
+	return nil
+}
+
+func queueStrategyForSession(session model.SimpleListeningSession) QueueStrategy {
+	strategy := FindQueueStrategy(session.QueueStrategy)
+	if strategy == nil {
+		return FindQueueStrategy(defaultQueueStrategy)
+	}
+
+	return strategy
+}
+
+func SetQueueStrategy(session model.SimpleListeningSession, user model.SimpleUser, strategyName string) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the queue strategy.")
+	}
+
+	if FindQueueStrategy(strategyName) == nil {
+		return NewUserError("Unknown queue strategy.")
+	}
+
+	if strategyName == session.QueueStrategy {
+		return nil
+	}
+
+	session.QueueStrategy = strategyName
+	database.GetConnection().Model(&session).Update("queue_strategy", strategyName)
+
+	publishSessionEvent(session, EventQueueReordered, "")
+
+	return nil
+}
+
+// Plays requests strictly in the order they were made. Votes are ignored.
+type fifoQueueStrategy struct{}
+
+func (fifoQueueStrategy) Name() string {
+	return "FIFO"
+}
+
+func (fifoQueueStrategy) Description() string {
+	return "First come, first served"
+}
+
+func (fifoQueueStrategy) orderExpression() string {
+	return "song_requests.created_at"
+}
+
+// Prefers requests of guests that made fewer requests in this session. Every vote counts like one request less.
+type weightedFairnessQueueStrategy struct{}
+
+func (weightedFairnessQueueStrategy) Name() string {
+	return "WEIGHTED_FAIRNESS"
+}
+
+func (weightedFairnessQueueStrategy) Description() string {
+	return "Guests with fewer requests go first, votes move tracks up"
+}
+
+func (weightedFairnessQueueStrategy) orderExpression() string {
+	return "song_requests.weight - song_requests.vote_score"
+}
+
+// Alternates strictly between the guests that currently have requests in the queue. Votes are ignored.
+type roundRobinQueueStrategy struct{}
+
+func (roundRobinQueueStrategy) Name() string {
+	return "ROUND_ROBIN"
+}
+
+func (roundRobinQueueStrategy) Description() string {
+	return "Take turns between all guests with requests in the queue"
+}
+
+func (roundRobinQueueStrategy) orderExpression() string {
+	return "row_number() over (partition by song_requests.guest_id, song_requests.requested_by order by song_requests.created_at)"
+}
+
+// Like weighted fairness, but earlier requests of a guest count less the longer ago they were made.
+// A request made one half-life before another one only counts half.
+type timeDecayedFairnessQueueStrategy struct{}
+
+const timeDecayedFairnessHalfLifeSeconds = 30 * 60
+
+func (timeDecayedFairnessQueueStrategy) Name() string {
+	return "TIME_DECAYED_FAIRNESS"
+}
+
+func (timeDecayedFairnessQueueStrategy) Description() string {
+	return "Guests who did not request anything recently go first, votes move tracks up"
+}
+
+func (timeDecayedFairnessQueueStrategy) orderExpression() string {
+	return fmt.Sprintf(`(select coalesce(sum(power(0.5, extract(epoch from (song_requests.created_at - earlier_requests.created_at)) / %d)), 0)
+		from song_requests earlier_requests
+		where earlier_requests.session_id = song_requests.session_id
+		and earlier_requests.guest_id is not distinct from song_requests.guest_id
+		and earlier_requests.requested_by = song_requests.requested_by
+		and earlier_requests.created_at < song_requests.created_at
+		and earlier_requests.deleted_at is null) - song_requests.vote_score`, timeDecayedFairnessHalfLifeSeconds)
+}
@@ -0,0 +1,54 @@
+package listeningSession
+
+import (
+	"testing"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+func TestQueueStrategiesHaveUniqueNames(t *testing.T) {
+
+	names := map[string]bool{}
+	for _, strategy := range QueueStrategies() {
+		if strategy.Name() == "" || strategy.Description() == "" || strategy.orderExpression() == "" {
+			t.Errorf("strategy %T is missing its name, description or order", strategy)
+		}
+		if names[strategy.Name()] {
+			t.Errorf("strategy name %s is used twice", strategy.Name())
+		}
+		names[strategy.Name()] = true
+	}
+}
+
+func TestFindQueueStrategy(t *testing.T) {
+
+	for _, strategy := range QueueStrategies() {
+		if found := FindQueueStrategy(strategy.Name()); found != strategy {
+			t.Errorf("FindQueueStrategy(%s) = %v, want %v", strategy.Name(), found, strategy)
+		}
+	}
+
+	if found := FindQueueStrategy("UNKNOWN"); found != nil {
+		t.Errorf("FindQueueStrategy(UNKNOWN) = %v, want nil", found)
+	}
+}
+
+func TestQueueStrategyForSessionFallsBackToDefault(t *testing.T) {
+
+	tests := []struct {
+		queueStrategy string
+		want          string
+	}{
+		{"FIFO", "FIFO"},
+		{"ROUND_ROBIN", "ROUND_ROBIN"},
+		{"", defaultQueueStrategy},
+		{"REMOVED_STRATEGY", defaultQueueStrategy},
+	}
+
+	for _, test := range tests {
+		session := model.SimpleListeningSession{QueueStrategy: test.queueStrategy}
+		if got := queueStrategyForSession(session).Name(); got != test.want {
+			t.Errorf("strategy for session with %q is %s, want %s", test.queueStrategy, got, test.want)
+		}
+	}
+}
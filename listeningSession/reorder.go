@@ -0,0 +1,182 @@
+package listeningSession
+
+import (
+	"errors"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+// Moves a request to the given position in the queue. Position 0 is the currently playing track, so position 1 means "play next".
+// Behind the lookahead, only the moved request is pinned. All other requests keep the order of the queue strategy.
+func MoveRequest(session model.FullListeningSession, user model.SimpleUser, spotifyTrackId string, position int) (spotifeteError *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can move requests.")
+	}
+
+	if position < 1 {
+		return NewUserError("Requests can only be moved behind the track that is currently playing.")
+	}
+
+	moveTask := func(tx *gorm.DB) error {
+		queue, err := GetFullQueueInTransaction(session.SimpleListeningSession, tx)
+		if err != nil {
+			return err
+		}
+
+		currentPosition := findPositionOfTrackInQueue(queue, spotifyTrackId)
+		if currentPosition < 0 {
+			spotifeteError = NewUserError("Request not found in queue.")
+			return errors.New("rolling back transaction")
+		}
+		if currentPosition == 0 {
+			spotifeteError = NewUserError("The track that is currently playing can not be moved.")
+			return errors.New("rolling back transaction")
+		}
+
+		reorderedQueue := moveRequestInQueue(queue, currentPosition, position)
+		spotifeteError = checkPushedRequestsStayAhead(reorderedQueue, queue[currentPosition])
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		return updateQueueOrderInTransaction(session.SimpleListeningSession, reorderedQueue, queue[currentPosition].ID, tx)
+	}
+
+	err := database.GetConnection().Transaction(moveTask)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if err != nil {
+		return NewInternalError("could not move request", err)
+	}
+
+	publish(QueueChanged{Session: session})
+	publishSessionEvent(session.SimpleListeningSession, EventQueueReordered, spotifyTrackId)
+
+	return nil
+}
+
+func PlayNext(session model.FullListeningSession, user model.SimpleUser, spotifyTrackId string) *SpotifeteError {
+	return MoveRequest(session, user, spotifyTrackId, 1)
+}
+
+func findPositionOfTrackInQueue(queue []model.SongRequest, spotifyTrackId string) int {
+
+	for position, request := range queue {
+		if request.SpotifyTrackId == spotifyTrackId {
+			return position
+		}
+	}
+
+	return -1
+}
+
+func moveRequestInQueue(queue []model.SongRequest, from int, to int) []model.SongRequest {
+
+	if to >= len(queue) {
+		to = len(queue) - 1
+	}
+
+	movedRequest := queue[from]
+	reorderedQueue := append([]model.SongRequest{}, queue[:from]...)
+	reorderedQueue = append(reorderedQueue, queue[from+1:]...)
+
+	reorderedQueue = append(reorderedQueue[:to], append([]model.SongRequest{movedRequest}, reorderedQueue[to:]...)...)
+	return reorderedQueue
+}
+
+// Spotify can not take a track out of its queue again, so requests that were added to it have to keep their position
+func checkPushedRequestsStayAhead(reorderedQueue []model.SongRequest, movedRequest model.SongRequest) *SpotifeteError {
+
+	if movedRequest.PushedToPlayer {
+		return NewUserError("This track has already been added to the Spotify queue and can not be moved anymore.")
+	}
+
+	movedPosition := findPositionOfTrackInQueue(reorderedQueue, movedRequest.SpotifyTrackId)
+	for _, request := range reorderedQueue[movedPosition+1:] {
+		if request.PushedToPlayer {
+			return NewUserError("Tracks can not be moved ahead of tracks that have already been added to the Spotify queue.")
+		}
+	}
+
+	return nil
+}
+
+// The currently playing request is never touched. Requests within the lookahead are locked in their new order.
+// Requests that were added to the Spotify queue stay locked, even if they are pushed out of the lookahead.
+func updateQueueOrderInTransaction(session model.SimpleListeningSession, queue []model.SongRequest, movedRequestId uint, tx *gorm.DB) error {
+
+	lookahead := queueLookahead(session)
+	for position := 1; position < len(queue); position++ {
+		request := &queue[position]
+
+		var updates map[string]interface{}
+		if position < lookahead {
+			request.Locked = true
+			request.Weight = int64(position)
+			request.ManualPosition = nil
+			updates = map[string]interface{}{
+				"locked":          true,
+				"weight":          position,
+				"manual_position": nil,
+			}
+		} else {
+			if request.ID == movedRequestId {
+				// The actual manual position is set by repinRequestsInTransaction
+				request.ManualPosition = &position
+			}
+
+			if request.Locked && !request.PushedToPlayer {
+				weight, err := getUnlockedWeightInTransaction(session, *request, tx)
+				if err != nil {
+					return err
+				}
+
+				request.Locked = false
+				request.Weight = weight
+				updates = map[string]interface{}{
+					"locked": false,
+					"weight": weight,
+				}
+			}
+		}
+
+		if updates == nil {
+			continue
+		}
+
+		err := tx.Model(request).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return repinRequestsInTransaction(queue, tx)
+}
+
+// A pinned request stays behind as many requests ordered by the queue strategy as there are ahead of it in the given queue.
+// This has to be done again whenever requests are locked or unlocked, otherwise pinned requests would move.
+func repinRequestsInTransaction(queue []model.SongRequest, tx *gorm.DB) error {
+
+	strategyOrderedRequestsAhead := 0
+	for _, request := range queue {
+		if request.Locked {
+			continue
+		}
+
+		if request.ManualPosition == nil {
+			strategyOrderedRequestsAhead++
+			continue
+		}
+
+		err := tx.Model(&request).Update("manual_position", strategyOrderedRequestsAhead).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package listeningSession
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+func SetRequestLimits(session model.SimpleListeningSession, user model.SimpleUser, limits model.RequestLimits) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the request limits.")
+	}
+
+	if limits.MaxRequestsPerWindow > 0 && limits.RequestWindowMinutes == 0 {
+		return NewUserError("The time window for the request limit must not be empty.")
+	}
+
+	session.RequestLimits = limits
+	database.GetConnection().Model(&session).Updates(map[string]interface{}{
+		"max_unplayed_requests":        limits.MaxUnplayedRequests,
+		"max_requests_per_window":      limits.MaxRequestsPerWindow,
+		"request_window_minutes":       limits.RequestWindowMinutes,
+		"min_seconds_between_requests": limits.MinSecondsBetweenRequests,
+	})
+
+	return nil
+}
+
+func checkRequestLimitsInTransaction(session model.SimpleListeningSession, guest model.Guest, tx *gorm.DB) *SpotifeteError {
+
+	spotifeteError := checkMaxUnplayedRequestsInTransaction(session, guest, tx)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	spotifeteError = checkMaxRequestsPerWindowInTransaction(session, guest, tx)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	return checkMinTimeBetweenRequestsInTransaction(session, guest, tx)
+}
+
+func checkMaxUnplayedRequestsInTransaction(session model.SimpleListeningSession, guest model.Guest, tx *gorm.DB) *SpotifeteError {
+
+	maxUnplayedRequests := session.MaxUnplayedRequests
+	if maxUnplayedRequests == 0 {
+		return nil
+	}
+
+	var unplayedRequestCount int64
+	err := tx.Model(model.SongRequest{}).
+		Where("session_id = ? AND guest_id = ? AND played = false AND approval_status <> ?", session.ID, guest.ID, model.ApprovalStatusRejected).
+		Count(&unplayedRequestCount).Error
+	if err != nil {
+		return NewInternalError("Could not get number of unplayed requests for guest.", err)
+	}
+
+	if unplayedRequestCount >= int64(maxUnplayedRequests) {
+		return NewUserError(fmt.Sprintf("You already have %d tracks in the queue. You can request again once one of them has been played.", unplayedRequestCount))
+	}
+
+	return nil
+}
+
+func checkMaxRequestsPerWindowInTransaction(session model.SimpleListeningSession, guest model.Guest, tx *gorm.DB) *SpotifeteError {
+
+	maxRequestsPerWindow := int(session.MaxRequestsPerWindow)
+	if maxRequestsPerWindow == 0 {
+		return nil
+	}
+
+	window := time.Duration(session.RequestWindowMinutes) * time.Minute
+	var requestsInWindow []model.SongRequest
+	err := tx.Where("session_id = ? AND guest_id = ? AND created_at > ? AND approval_status <> ?", session.ID, guest.ID, time.Now().Add(-window), model.ApprovalStatusRejected).
+		Order("created_at desc").
+		Find(&requestsInWindow).Error
+	if err != nil {
+		return NewInternalError("Could not get recent requests for guest.", err)
+	}
+
+	if len(requestsInWindow) < maxRequestsPerWindow {
+		return nil
+	}
+
+	// As soon as this request leaves the window, the guest is below the limit again
+	limitingRequest := requestsInWindow[maxRequestsPerWindow-1]
+	nextRequestPossibleAt := limitingRequest.CreatedAt.Add(window)
+
+	return NewUserError(fmt.Sprintf("You can only request %d tracks per %d minutes. You can request again in %s.",
+		maxRequestsPerWindow,
+		session.RequestWindowMinutes,
+		formatWaitTime(time.Until(nextRequestPossibleAt))))
+}
+
+func checkMinTimeBetweenRequestsInTransaction(session model.SimpleListeningSession, guest model.Guest, tx *gorm.DB) *SpotifeteError {
+
+	minTimeBetweenRequests := time.Duration(session.MinSecondsBetweenRequests) * time.Second
+	if minTimeBetweenRequests == 0 {
+		return nil
+	}
+
+	var lastRequests []model.SongRequest
+	err := tx.Where("session_id = ? AND guest_id = ? AND approval_status <> ?", session.ID, guest.ID, model.ApprovalStatusRejected).
+		Order("created_at desc").
+		Limit(1).
+		Find(&lastRequests).Error
+	if err != nil {
+		return NewInternalError("Could not get last request for guest.", err)
+	}
+
+	if len(lastRequests) == 0 {
+		return nil
+	}
+
+	nextRequestPossibleAt := lastRequests[0].CreatedAt.Add(minTimeBetweenRequests)
+	if time.Now().Before(nextRequestPossibleAt) {
+		return NewUserError(fmt.Sprintf("Slow down! You can request again in %s.", formatWaitTime(time.Until(nextRequestPossibleAt))))
+	}
+
+	return nil
+}
+
+func formatWaitTime(waitTime time.Duration) string {
+	if waitTime < time.Minute {
+		seconds := int(math.Ceil(waitTime.Seconds()))
+		if seconds <= 1 {
+			return "1 second"
+		}
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+
+	minutes := int(math.Ceil(waitTime.Minutes()))
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,120 @@
+package listeningSession
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+func TestFormatWaitTime(t *testing.T) {
+
+	tests := []struct {
+		waitTime time.Duration
+		want     string
+	}{
+		{0, "1 second"},
+		{300 * time.Millisecond, "1 second"},
+		{time.Second, "1 second"},
+		{1500 * time.Millisecond, "2 seconds"},
+		{59 * time.Second, "59 seconds"},
+		{59*time.Second + 500*time.Millisecond, "60 seconds"},
+		{time.Minute, "1 minute"},
+		{61 * time.Second, "2 minutes"},
+		{90 * time.Minute, "90 minutes"},
+	}
+
+	for _, test := range tests {
+		if got := formatWaitTime(test.waitTime); got != test.want {
+			t.Errorf("formatWaitTime(%s) = %q, want %q", test.waitTime, got, test.want)
+		}
+	}
+}
+
+func (testSession testSession) setRequestLimits(t *testing.T, limits model.RequestLimits) testSession {
+
+	t.Helper()
+
+	spotifeteError := SetRequestLimits(testSession.session.SimpleListeningSession, testSession.owner, limits)
+	if spotifeteError != nil {
+		t.Fatalf("could not set request limits: %s", spotifeteError.MessageForUser)
+	}
+
+	testSession.session = reloadSession(t, testSession.session.ID)
+	return testSession
+}
+
+func assertRequestRefused(t *testing.T, testSession testSession, trackId string, guest model.Guest, wantMessage string) {
+
+	t.Helper()
+
+	_, spotifeteError := RequestSong(testSession.session, trackId, guest)
+	if spotifeteError == nil {
+		t.Fatalf("requesting %s succeeded, want it refused", trackId)
+	}
+	if !strings.Contains(spotifeteError.MessageForUser, wantMessage) {
+		t.Errorf("request was refused with %q, want a message containing %q", spotifeteError.MessageForUser, wantMessage)
+	}
+}
+
+func TestMaxUnplayedRequestsIsEnforced(t *testing.T) {
+
+	testSession := newTestSession(t).setRequestLimits(t, model.RequestLimits{MaxUnplayedRequests: 2})
+	guest := testSession.joinAsGuest(t, "Guest")
+	otherGuest := testSession.joinAsGuest(t, "Other guest")
+
+	testSession.request(t, "track1", guest)
+	testSession.request(t, "track2", guest)
+
+	assertRequestRefused(t, testSession, "track3", guest, "You already have 2 tracks in the queue.")
+	testSession.request(t, "track3", otherGuest)
+}
+
+func TestMaxRequestsPerWindowIsEnforced(t *testing.T) {
+
+	testSession := newTestSession(t).setRequestLimits(t, model.RequestLimits{MaxRequestsPerWindow: 2, RequestWindowMinutes: 60})
+	guest := testSession.joinAsGuest(t, "Guest")
+
+	testSession.request(t, "track1", guest)
+	testSession.request(t, "track2", guest)
+
+	assertRequestRefused(t, testSession, "track3", guest, "You can only request 2 tracks per 60 minutes. You can request again in 60 minutes.")
+}
+
+func TestMinTimeBetweenRequestsIsEnforced(t *testing.T) {
+
+	testSession := newTestSession(t).setRequestLimits(t, model.RequestLimits{MinSecondsBetweenRequests: 60})
+	guest := testSession.joinAsGuest(t, "Guest")
+
+	testSession.request(t, "track1", guest)
+
+	assertRequestRefused(t, testSession, "track2", guest, "Slow down!")
+}
+
+func TestRejectedRequestsDoNotCountAgainstLimits(t *testing.T) {
+
+	testSession := newTestSession(t).setRequestLimits(t, model.RequestLimits{
+		MaxUnplayedRequests:       1,
+		MaxRequestsPerWindow:      1,
+		RequestWindowMinutes:      60,
+		MinSecondsBetweenRequests: 60,
+	})
+	spotifeteError := SetModerated(testSession.session, testSession.owner, true)
+	if spotifeteError != nil {
+		t.Fatalf("could not enable moderation: %s", spotifeteError.MessageForUser)
+	}
+	testSession.session = reloadSession(t, testSession.session.ID)
+	guest := testSession.joinAsGuest(t, "Guest")
+
+	testSession.request(t, "track1", guest)
+	spotifeteError = RejectRequest(testSession.session.SimpleListeningSession, testSession.owner, "track1", "")
+	if spotifeteError != nil {
+		t.Fatalf("could not reject request: %s", spotifeteError.MessageForUser)
+	}
+
+	request := testSession.request(t, "track2", guest)
+	if request.ApprovalStatus != model.ApprovalStatusPending {
+		t.Errorf("request is %s, want it pending", request.ApprovalStatus)
+	}
+}
@@ -0,0 +1,221 @@
+package listeningSession
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/zmb3/spotify"
+	"gorm.io/gorm"
+)
+
+func GetSessionRules(session model.SimpleListeningSession) ([]model.SessionRule, *SpotifeteError) {
+
+	rules, err := findSessionRulesInTransaction(session, database.GetConnection())
+	if err != nil {
+		return nil, NewInternalError("Could not fetch session rules from database", err)
+	}
+
+	return rules, nil
+}
+
+func findSessionRulesInTransaction(session model.SimpleListeningSession, tx *gorm.DB) ([]model.SessionRule, error) {
+
+	var rules []model.SessionRule
+	err := tx.Where(model.SessionRule{SessionId: session.ID}).Order("created_at asc").Find(&rules).Error
+	return rules, err
+}
+
+func AddSessionRule(session model.FullListeningSession, user model.SimpleUser, ruleType string, subject string, spotifyId string) (model.SessionRule, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return model.SessionRule{}, NewUserError("Only the session owner can change the session rules.")
+	}
+
+	if ruleType != model.SessionRuleTypeBlock && ruleType != model.SessionRuleTypeAllow {
+		return model.SessionRule{}, NewUserError("Rule type must be either BLOCK or ALLOW.")
+	}
+
+	existingRule, spotifeteError := findSessionRule(session.SimpleListeningSession, ruleType, subject, spotifyId)
+	if spotifeteError != nil {
+		return model.SessionRule{}, spotifeteError
+	}
+	if existingRule != nil {
+		return model.SessionRule{}, NewUserError("This rule already exists.")
+	}
+
+	name, spotifeteError := getNameOfRuleSubject(session, subject, spotify.ID(spotifyId))
+	if spotifeteError != nil {
+		return model.SessionRule{}, spotifeteError
+	}
+
+	newRule := model.SessionRule{
+		SessionId: session.ID,
+		RuleType:  ruleType,
+		Subject:   subject,
+		SpotifyId: spotifyId,
+		Name:      name,
+	}
+
+	err := database.GetConnection().Create(&newRule).Error
+	if err != nil {
+		return model.SessionRule{}, NewInternalError("could not save new session rule", err)
+	}
+
+	return newRule, nil
+}
+
+func getNameOfRuleSubject(session model.FullListeningSession, subject string, spotifyId spotify.ID) (string, *SpotifeteError) {
+	client := Client(session)
+
+	switch subject {
+	case model.SessionRuleSubjectTrack:
+		track, err := client.GetTrack(spotifyId)
+		if err != nil {
+			return "", NewError("Could not get track information from Spotify.", err, http.StatusInternalServerError)
+		}
+		return track.Name, nil
+	case model.SessionRuleSubjectArtist:
+		artist, err := client.GetArtist(spotifyId)
+		if err != nil {
+			return "", NewError("Could not get artist information from Spotify.", err, http.StatusInternalServerError)
+		}
+		return artist.Name, nil
+	case model.SessionRuleSubjectAlbum:
+		album, err := client.GetAlbum(spotifyId)
+		if err != nil {
+			return "", NewError("Could not get album information from Spotify.", err, http.StatusInternalServerError)
+		}
+		return album.Name, nil
+	default:
+		return "", NewUserError("Rule subject must be one of TRACK, ARTIST or ALBUM.")
+	}
+}
+
+func RemoveSessionRule(session model.SimpleListeningSession, user model.SimpleUser, ruleType string, subject string, spotifyId string) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the session rules.")
+	}
+
+	ruleToDelete, spotifeteError := findSessionRule(session, ruleType, subject, spotifyId)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if ruleToDelete == nil {
+		return NewUserError("Rule not found.")
+	}
+
+	err := database.GetConnection().Unscoped().Delete(ruleToDelete).Error
+	if err != nil {
+		return NewInternalError("could not delete session rule", err)
+	}
+
+	return nil
+}
+
+func findSessionRule(session model.SimpleListeningSession, ruleType string, subject string, spotifyId string) (*model.SessionRule, *SpotifeteError) {
+
+	var rules []model.SessionRule
+	err := database.GetConnection().Where(model.SessionRule{
+		SessionId: session.ID,
+		RuleType:  ruleType,
+		Subject:   subject,
+		SpotifyId: spotifyId,
+	}).Find(&rules).Error
+	if err != nil {
+		return nil, NewInternalError("Could not fetch session rule from database", err)
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	return &rules[0], nil
+}
+
+func checkSessionRules(rules []model.SessionRule, track spotify.FullTrack) *SpotifeteError {
+
+	violation := findSessionRuleViolation(rules, track)
+	if violation != "" {
+		return NewUserError(violation)
+	}
+
+	return nil
+}
+
+// Block rules always win. As soon as there is at least one allow rule, a track has to match one of them.
+// Returns a message for the guest if the track is not allowed and an empty string otherwise.
+func findSessionRuleViolation(rules []model.SessionRule, track spotify.FullTrack) string {
+
+	hasAllowRules := false
+	matchesAllowRule := false
+	for _, rule := range rules {
+		matches := sessionRuleMatchesTrack(rule, track)
+
+		if rule.RuleType == model.SessionRuleTypeBlock && matches {
+			return blockedByRuleMessage(rule)
+		}
+
+		if rule.RuleType == model.SessionRuleTypeAllow {
+			hasAllowRules = true
+			matchesAllowRule = matchesAllowRule || matches
+		}
+	}
+
+	if hasAllowRules && !matchesAllowRule {
+		return "Only selected tracks, artists and albums can be requested in this session."
+	}
+
+	return ""
+}
+
+func sessionRuleMatchesTrack(rule model.SessionRule, track spotify.FullTrack) bool {
+
+	switch rule.Subject {
+	case model.SessionRuleSubjectTrack:
+		return rule.SpotifyId == track.ID.String()
+	case model.SessionRuleSubjectArtist:
+		for _, artist := range track.Artists {
+			if rule.SpotifyId == artist.ID.String() {
+				return true
+			}
+		}
+		return false
+	case model.SessionRuleSubjectAlbum:
+		return rule.SpotifyId == track.Album.ID.String()
+	default:
+		return false
+	}
+}
+
+func blockedByRuleMessage(rule model.SessionRule) string {
+
+	switch rule.Subject {
+	case model.SessionRuleSubjectArtist:
+		if rule.Name != "" {
+			return fmt.Sprintf("%s is blocked in this session.", rule.Name)
+		}
+		return "This artist is blocked in this session."
+	case model.SessionRuleSubjectAlbum:
+		return "This album is blocked in this session."
+	default:
+		return "This track is blocked in this session."
+	}
+}
+
+func filterTracksAllowedBySessionRules(tracks []spotify.FullTrack, rules []model.SessionRule) []spotify.FullTrack {
+
+	if len(rules) == 0 {
+		return tracks
+	}
+
+	var allowedTracks []spotify.FullTrack
+	for _, track := range tracks {
+		if findSessionRuleViolation(rules, track) == "" {
+			allowedTracks = append(allowedTracks, track)
+		}
+	}
+
+	return allowedTracks
+}
@@ -2,60 +2,65 @@ package listeningSession
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/partyoffice/spotifete/database/model"
 	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
 	"github.com/partyoffice/spotifete/users"
-	"github.com/zmb3/spotify"
 )
 
-func SearchTrack(listeningSession model.FullListeningSession, query string, limit int) ([]model.TrackMetadata, *SpotifeteError) {
-	client := Client(listeningSession)
-	return searchTrack(*client, query, limit)
+type TrackSearchResult struct {
+	model.TrackMetadata
+	Blocked       bool   `json:"blocked"`
+	BlockedReason string `json:"blocked_reason,omitempty"`
 }
 
-func searchTrack(client spotify.Client, query string, limit int) ([]model.TrackMetadata, *SpotifeteError) {
-	cleanedQuery := strings.TrimSpace(query) + "*"
+func SearchTrack(listeningSession model.FullListeningSession, query string, limit int) ([]TrackSearchResult, *SpotifeteError) {
+	client := Client(listeningSession)
 
-	currentUser, err := client.CurrentUser()
-	if err != nil {
-		return nil, NewError("Could not fetch information on session owner from Spotify.", err, http.StatusInternalServerError)
+	rules, spotifeteError := GetSessionRules(listeningSession.SimpleListeningSession)
+	if spotifeteError != nil {
+		return nil, spotifeteError
 	}
 
-	result, err := client.SearchOpt(cleanedQuery, spotify.SearchTypeTrack, &spotify.Options{
-		Limit:   &limit,
-		Country: &currentUser.Country,
-	})
+	return searchTrack(client, query, limit, Market(listeningSession), listeningSession.SimpleListeningSession, rules)
+}
+
+func searchTrack(client spotifyApi.Client, query string, limit int, market string, session model.SimpleListeningSession, rules []model.SessionRule) ([]TrackSearchResult, *SpotifeteError) {
+	tracks, err := searchTracksCached(client, query, limit, market)
 	if err != nil {
 		return nil, NewError("Could not search for track on Spotify.", err, http.StatusInternalServerError)
 	}
 
-	var resultMetadata []model.TrackMetadata
-	for _, track := range result.Tracks.Tracks {
-		metadata := model.TrackMetadata{}.SetMetadata(track)
-		resultMetadata = append(resultMetadata, metadata)
+	var searchResults []TrackSearchResult
+	for _, track := range tracks {
+		blockedReason := findSessionRuleViolation(rules, track)
+		if blockedReason == "" {
+			blockedReason = findContentFilterViolation(session, track)
+		}
+		searchResults = append(searchResults, TrackSearchResult{
+			TrackMetadata: model.TrackMetadata{}.SetMetadata(track),
+			Blocked:       blockedReason != "",
+			BlockedReason: blockedReason,
+		})
 	}
 
-	return resultMetadata, nil
+	return searchResults, nil
 }
 
 func SearchPlaylist(listeningSession model.FullListeningSession, query string, limit int) ([]model.PlaylistMetadata, *SpotifeteError) {
 	client := users.Client(listeningSession.Owner)
-	return searchPlaylist(*client, query, limit)
+	return searchPlaylist(client, query, limit, Market(listeningSession))
 }
 
-func searchPlaylist(client spotify.Client, query string, limit int) ([]model.PlaylistMetadata, *SpotifeteError) {
-	cleanedQuery := strings.TrimSpace(query) + "*"
-	result, err := client.SearchOpt(cleanedQuery, spotify.SearchTypePlaylist, &spotify.Options{
-		Limit: &limit,
-	})
+func searchPlaylist(client spotifyApi.Client, query string, limit int, market string) ([]model.PlaylistMetadata, *SpotifeteError) {
+	playlists, err := searchPlaylistsCached(client, query, limit, market)
 	if err != nil {
 		return nil, NewError("Could not search for track on Spotify.", err, http.StatusInternalServerError)
 	}
 
 	var resultMetadata []model.PlaylistMetadata
-	for _, playlist := range result.Playlists.Playlists {
+	for _, playlist := range playlists {
 		metadata := model.PlaylistMetadata{}.FromSimplePlaylist(playlist)
 		resultMetadata = append(resultMetadata, metadata)
 	}
@@ -0,0 +1,24 @@
+package listeningSession
+
+import (
+	"sync"
+
+	"github.com/partyoffice/spotifete/database/model"
+)
+
+var sessionMutexes sync.Map
+
+// Makes sure that only one goroutine at a time advances the queue of a session, e.g. the poller and an owner skipping a track
+func lockSession(session model.SimpleListeningSession) (unlock func()) {
+
+	mutex, _ := sessionMutexes.LoadOrStore(session.ID, &sync.Mutex{})
+	sessionMutex := mutex.(*sync.Mutex)
+	sessionMutex.Lock()
+
+	return sessionMutex.Unlock
+}
+
+// Closed sessions are not updated anymore, so their mutex is not needed anymore either
+func forgetSessionLock(session model.SimpleListeningSession) {
+	sessionMutexes.Delete(session.ID)
+}
@@ -75,11 +75,27 @@ func GetLimitedQueueInTransaction(session model.SimpleListeningSession, limit in
 func buildGetQueueQuery(session model.SimpleListeningSession, tx *gorm.DB) *gorm.DB {
 
 	filter := map[string]interface{}{
-		"session_id": session.ID,
-		"played":     false,
+		"session_id":      session.ID,
+		"played":          false,
+		"approval_status": model.ApprovalStatusApproved,
 	}
 
-	return tx.Where(filter).Order("locked desc, weight asc, created_at asc")
+	// Locked requests are ordered by their weight only, so the queue strategy can not move them.
+	// Everything else is ranked by the queue strategy, except for requests the owner moved by hand. Their manual position
+	// is the number of requests ranked by the queue strategy that stay ahead of them.
+	strategy := queueStrategyForSession(session)
+	strategyRanks := fmt.Sprintf(`LEFT JOIN (
+		select queued_requests.id, row_number() over (order by queued_requests.strategy_value asc, queued_requests.created_at asc) as strategy_rank
+		from (
+			select song_requests.id, song_requests.created_at, song_requests.locked, song_requests.manual_position, %s as strategy_value
+			from song_requests
+			where song_requests.session_id = ? and song_requests.played = false and song_requests.approval_status = ? and song_requests.deleted_at is null
+		) queued_requests
+		where not queued_requests.locked and queued_requests.manual_position is null
+	) strategy_ranks ON strategy_ranks.id = song_requests.id`, strategy.orderExpression())
+	order := "song_requests.locked desc, case when song_requests.locked then song_requests.weight end asc, coalesce(song_requests.manual_position + 0.5, strategy_ranks.strategy_rank) asc, song_requests.created_at asc"
+
+	return tx.Joins(strategyRanks, session.ID, model.ApprovalStatusApproved).Where(filter).Order(order)
 }
 
 func GetQueueLastUpdated(session model.SimpleListeningSession) time.Time {
@@ -96,10 +112,29 @@ func GetQueueLastUpdated(session model.SimpleListeningSession) time.Time {
 	}
 }
 
-func isTrackInQueue(session model.SimpleListeningSession, trackId string, tx *gorm.DB) bool {
+// Pending requests are not in the queue yet, so guests can neither vote for them nor have their duplicates merged into them
+func findQueuedRequestForTrackInTransaction(session model.SimpleListeningSession, trackId string, tx *gorm.DB) (*model.SongRequest, error) {
 	var duplicateRequestsForTrack []model.SongRequest
-	tx.Where("played = false AND session_id = ? AND spotify_track_id = ?", session.ID, trackId).Find(&duplicateRequestsForTrack)
-	return len(duplicateRequestsForTrack) > 0
+	err := tx.Where("played = false AND session_id = ? AND spotify_track_id = ? AND approval_status = ?", session.ID, trackId, model.ApprovalStatusApproved).Find(&duplicateRequestsForTrack).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(duplicateRequestsForTrack) == 0 {
+		return nil, nil
+	} else {
+		return &duplicateRequestsForTrack[0], nil
+	}
+}
+
+func isTrackPendingInTransaction(session model.SimpleListeningSession, trackId string, tx *gorm.DB) (bool, error) {
+	pendingRequestCount, err := FindSongRequestCountInTransaction(map[string]interface{}{
+		"session_id":       session.ID,
+		"spotify_track_id": trackId,
+		"played":           false,
+		"approval_status":  model.ApprovalStatusPending,
+	}, tx)
+	return pendingRequestCount > 0, err
 }
 
 func GetDistinctRequestedTracks(session model.SimpleListeningSession) (trackIds []spotify.ID) {
@@ -109,7 +144,8 @@ func GetDistinctRequestedTracks(session model.SimpleListeningSession) (trackIds
 
 	var results []Result
 	database.GetConnection().Table("song_requests").Select("distinct spotify_track_id").Where(model.SongRequest{
-		SessionId: session.ID,
+		SessionId:      session.ID,
+		ApprovalStatus: model.ApprovalStatusApproved,
 	}).Scan(&results)
 
 	for _, result := range results {
@@ -125,6 +161,7 @@ func DeleteRequestFromQueue(session model.SimpleListeningSession, spotifyTrackId
 		"session_id":       session.ID,
 		"spotify_track_id": spotifyTrackId,
 		"played":           false,
+		"approval_status":  model.ApprovalStatusApproved,
 	}
 
 	requestToDelete, err := FindSongRequest(filter)
@@ -140,5 +177,7 @@ func DeleteRequestFromQueue(session model.SimpleListeningSession, spotifyTrackId
 
 	database.GetConnection().Delete(requestToDelete)
 
+	publish(RequestDeleted{Session: session, Request: *requestToDelete})
+
 	return nil
 }
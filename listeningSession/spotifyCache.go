@@ -0,0 +1,198 @@
+package listeningSession
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/patrickmn/go-cache"
+	"github.com/zmb3/spotify"
+)
+
+// Guests often search for the same tracks, so search results and track lookups are shared between all sessions
+// for a while. Search results go out of date quickly, the details of a track hardly ever change.
+var (
+	searchCache      = newBoundedCache("search", 10*time.Minute, 1000)
+	trackLookupCache = newBoundedCache("track", time.Hour, 5000)
+)
+
+type CacheStats struct {
+	Name     string `json:"name"`
+	Items    int    `json:"items"`
+	MaxItems int    `json:"max_items"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+}
+
+func SpotifyCacheStats() []CacheStats {
+	return []CacheStats{
+		searchCache.stats(),
+		trackLookupCache.stats(),
+	}
+}
+
+// A go-cache that holds at most maxItems. If it is full, the items that would expire first are evicted.
+type boundedCache struct {
+	name     string
+	cache    *cache.Cache
+	maxItems int
+	// Only guards adding items, so the cache does not grow beyond maxItems while several requests add items at once
+	setMutex sync.Mutex
+	hits     atomic.Int64
+	misses   atomic.Int64
+}
+
+// Finding the items to evict means going through the whole cache, so a full cache makes room for several items at once
+const evictionBatchFraction = 10
+
+func newBoundedCache(name string, expiration time.Duration, maxItems int) *boundedCache {
+	return &boundedCache{
+		name:     name,
+		cache:    cache.New(expiration, 2*expiration),
+		maxItems: maxItems,
+	}
+}
+
+func (boundedCache *boundedCache) get(key string) (interface{}, bool) {
+
+	value, found := boundedCache.cache.Get(key)
+	if found {
+		boundedCache.hits.Add(1)
+	} else {
+		boundedCache.misses.Add(1)
+	}
+
+	return value, found
+}
+
+func (boundedCache *boundedCache) set(key string, value interface{}) {
+
+	boundedCache.setMutex.Lock()
+	defer boundedCache.setMutex.Unlock()
+
+	if boundedCache.cache.ItemCount() >= boundedCache.maxItems {
+		boundedCache.cache.DeleteExpired()
+	}
+	if boundedCache.cache.ItemCount() >= boundedCache.maxItems {
+		boundedCache.evictSoonestExpiring(boundedCache.maxItems/evictionBatchFraction + 1)
+	}
+
+	boundedCache.cache.SetDefault(key, value)
+}
+
+func (boundedCache *boundedCache) evictSoonestExpiring(count int) {
+
+	type expiringKey struct {
+		key        string
+		expiration int64
+	}
+
+	items := boundedCache.cache.Items()
+	keys := make([]expiringKey, 0, len(items))
+	for key, item := range items {
+		keys = append(keys, expiringKey{key: key, expiration: item.Expiration})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].expiration < keys[j].expiration
+	})
+
+	for i := 0; i < count && i < len(keys); i++ {
+		boundedCache.cache.Delete(keys[i].key)
+	}
+}
+
+func (boundedCache *boundedCache) stats() CacheStats {
+	return CacheStats{
+		Name:     boundedCache.name,
+		Items:    boundedCache.cache.ItemCount(),
+		MaxItems: boundedCache.maxItems,
+		Hits:     boundedCache.hits.Load(),
+		Misses:   boundedCache.misses.Load(),
+	}
+}
+
+func searchCacheKey(searchType spotify.SearchType, query string, market string, limit int) string {
+	normalizedQuery := strings.ToLower(strings.TrimSpace(query))
+	return strconv.Itoa(int(searchType)) + ":" + market + ":" + strconv.Itoa(limit) + ":" + normalizedQuery
+}
+
+func searchTracksCached(client spotifyApi.Client, query string, limit int, market string) ([]spotify.FullTrack, error) {
+
+	cacheKey := searchCacheKey(spotify.SearchTypeTrack, query, market, limit)
+	if cachedTracks, found := searchCache.get(cacheKey); found {
+		return cachedTracks.([]spotify.FullTrack), nil
+	}
+
+	result, err := client.SearchOpt(strings.TrimSpace(query)+"*", spotify.SearchTypeTrack, &spotify.Options{
+		Limit:   &limit,
+		Country: marketOption(market),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []spotify.FullTrack
+	if result.Tracks != nil {
+		tracks = result.Tracks.Tracks
+	}
+	searchCache.set(cacheKey, tracks)
+
+	// Guests usually request one of the tracks they just found
+	for _, track := range tracks {
+		trackLookupCache.set(trackCacheKey(track.ID.String(), market), track)
+	}
+
+	return tracks, nil
+}
+
+func searchPlaylistsCached(client spotifyApi.Client, query string, limit int, market string) ([]spotify.SimplePlaylist, error) {
+
+	cacheKey := searchCacheKey(spotify.SearchTypePlaylist, query, market, limit)
+	if cachedPlaylists, found := searchCache.get(cacheKey); found {
+		return cachedPlaylists.([]spotify.SimplePlaylist), nil
+	}
+
+	result, err := client.SearchOpt(strings.TrimSpace(query)+"*", spotify.SearchTypePlaylist, &spotify.Options{
+		Limit:   &limit,
+		Country: marketOption(market),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var playlists []spotify.SimplePlaylist
+	if result.Playlists != nil {
+		playlists = result.Playlists.Playlists
+	}
+	searchCache.set(cacheKey, playlists)
+
+	return playlists, nil
+}
+
+// Whether a track is playable depends on the market, so tracks are cached per market
+func trackCacheKey(trackId string, market string) string {
+	return trackId + ":" + market
+}
+
+func getTrackCached(client spotifyApi.Client, trackId string, market string) (spotify.FullTrack, error) {
+
+	cacheKey := trackCacheKey(trackId, market)
+	if cachedTrack, found := trackLookupCache.get(cacheKey); found {
+		return cachedTrack.(spotify.FullTrack), nil
+	}
+
+	track, err := client.GetTrackOpt(spotify.ID(trackId), &spotify.Options{
+		Country: marketOption(market),
+	})
+	if err != nil {
+		return spotify.FullTrack{}, err
+	}
+
+	trackLookupCache.set(cacheKey, *track)
+	return *track, nil
+}
@@ -1,23 +1,35 @@
 package listeningSession
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/partyoffice/spotifete/database"
 	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/partyoffice/spotifete/users"
 	"github.com/zmb3/spotify"
 	"gorm.io/gorm"
 )
 
-func AddOrUpdateTrackMetadataInTransaction(spotifyTrack spotify.FullTrack, tx *gorm.DB) (trackMetadata model.TrackMetadata, err error) {
+// Requests for tracks fetched less than this ago are checked against the saved metadata instead of asking Spotify
+const trackMetadataMaxAge = 24 * time.Hour
+
+func AddOrUpdateTrackMetadataInTransaction(spotifyTrack spotify.FullTrack, market string, tx *gorm.DB) (trackMetadata model.TrackMetadata, err error) {
 
 	knownTrackMetadata := GetTrackMetadataBySpotifyTrackIdInTransaction(spotifyTrack.ID.String(), tx)
 	if knownTrackMetadata != nil {
 
-		updatedTrackMetadata := knownTrackMetadata.SetMetadata(spotifyTrack)
+		updatedTrackMetadata := knownTrackMetadata.SetMetadata(spotifyTrack).SetPlayability(market, spotifyTrack.IsPlayable)
 		err = tx.Save(&updatedTrackMetadata).Error
 
 		return updatedTrackMetadata, err
 	} else {
 
-		newTrackMetadata := model.TrackMetadata{}.SetMetadata(spotifyTrack)
+		newTrackMetadata := model.TrackMetadata{}.SetMetadata(spotifyTrack).SetPlayability(market, spotifyTrack.IsPlayable)
 		err = tx.Create(&newTrackMetadata).Error
 
 		return newTrackMetadata, err
@@ -36,6 +48,101 @@ func GetTrackMetadataBySpotifyTrackIdInTransaction(trackId string, tx *gorm.DB)
 	}
 }
 
+func getTrackForRequestInTransaction(client spotifyApi.Client, trackId string, market string, tx *gorm.DB) (spotify.FullTrack, model.TrackMetadata, *SpotifeteError) {
+
+	knownTrackMetadata := GetTrackMetadataBySpotifyTrackIdInTransaction(trackId, tx)
+	if knownTrackMetadata != nil && knownTrackMetadata.IsFreshFor(market, trackMetadataMaxAge) {
+		return trackFromMetadata(*knownTrackMetadata), *knownTrackMetadata, nil
+	}
+
+	spotifyTrack, err := getTrackCached(client, trackId, market)
+	if err != nil {
+		return spotify.FullTrack{}, model.TrackMetadata{}, NewError("Could not get track information from spotify.", err, http.StatusInternalServerError)
+	}
+
+	updatedTrackMetadata, err := AddOrUpdateTrackMetadataInTransaction(spotifyTrack, market, tx)
+	if err != nil {
+		return spotify.FullTrack{}, model.TrackMetadata{}, NewInternalError("Could not get track metadata", err)
+	}
+
+	return spotifyTrack, updatedTrackMetadata, nil
+}
+
+// Restores everything requests are checked against: playability, session rules and content filters
+func trackFromMetadata(trackMetadata model.TrackMetadata) spotify.FullTrack {
+
+	var artists []spotify.SimpleArtist
+	for _, artistId := range strings.Split(trackMetadata.SpotifyArtistIds, ",") {
+		if artistId != "" {
+			artists = append(artists, spotify.SimpleArtist{ID: spotify.ID(artistId)})
+		}
+	}
+
+	playable := trackMetadata.Playable
+	return spotify.FullTrack{
+		SimpleTrack: spotify.SimpleTrack{
+			Artists:    artists,
+			Duration:   trackMetadata.DurationMs,
+			Explicit:   trackMetadata.Explicit,
+			ID:         spotify.ID(trackMetadata.SpotifyTrackId),
+			Name:       trackMetadata.TrackName,
+			PreviewURL: trackMetadata.PreviewUrl,
+		},
+		Album: spotify.SimpleAlbum{
+			ID:   spotify.ID(trackMetadata.SpotifyAlbumId),
+			Name: trackMetadata.AlbumName,
+		},
+		Popularity: trackMetadata.Popularity,
+		IsPlayable: &playable,
+	}
+}
+
+// Metadata saved before the duration was stored has a duration of 0, so start times of queued requests can not be estimated.
+// Tracks that were refreshed already are not selected again, so the job can simply be retried if Spotify fails.
+func refreshLegacyTrackMetadata() error {
+
+	var legacyTracks []struct {
+		SpotifyTrackId string
+		OwnerId        uint
+	}
+	err := database.GetConnection().
+		Table("song_requests").
+		Select("DISTINCT track_metadata.spotify_track_id, listening_sessions.owner_id").
+		Joins("JOIN track_metadata ON track_metadata.spotify_track_id = song_requests.spotify_track_id").
+		Joins("JOIN listening_sessions ON listening_sessions.id = song_requests.session_id").
+		Where("song_requests.deleted_at IS NULL AND song_requests.played = false").
+		Where("listening_sessions.active = true AND track_metadata.duration_ms = 0").
+		Scan(&legacyTracks).Error
+	if err != nil {
+		return err
+	}
+
+	for _, legacyTrack := range legacyTracks {
+		owner := users.FindSimpleUser(model.SimpleUser{BaseModel: model.BaseModel{ID: legacyTrack.OwnerId}})
+		if owner == nil {
+			continue
+		}
+
+		spotifyTrack, err := users.Client(*owner).GetTrack(spotify.ID(legacyTrack.SpotifyTrackId))
+		if err != nil {
+			return fmt.Errorf("could not fetch track %s: %w", legacyTrack.SpotifyTrackId, err)
+		}
+
+		knownTrackMetadata := GetTrackMetadataBySpotifyTrackIdInTransaction(legacyTrack.SpotifyTrackId, database.GetConnection())
+		if knownTrackMetadata == nil {
+			continue
+		}
+
+		updatedTrackMetadata := knownTrackMetadata.SetMetadata(*spotifyTrack)
+		err = database.GetConnection().Save(&updatedTrackMetadata).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func getTrackPlayCount(session model.SimpleListeningSession, spotifyTrackId string) (int64, error) {
 
 	return FindSongRequestCount(model.SongRequest{SessionId: session.ID, SpotifyTrackId: spotifyTrackId})
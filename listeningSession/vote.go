@@ -0,0 +1,160 @@
+package listeningSession
+
+import (
+	"errors"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+const (
+	Upvote   int64 = 1
+	Downvote int64 = -1
+)
+
+func VoteForRequest(session model.SimpleListeningSession, spotifyTrackId string, guest model.Guest, value int64) (spotifeteError *SpotifeteError) {
+
+	voteTask := func(tx *gorm.DB) error {
+		var request *model.SongRequest
+		request, spotifeteError = findRequestToVoteOnInTransaction(session, spotifyTrackId, tx)
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		_, spotifeteError = voteForRequestInTransaction(*request, guest, value, tx)
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		return nil
+	}
+
+	err := database.GetConnection().Transaction(voteTask)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if err != nil {
+		return NewInternalError("could not save vote", err)
+	}
+
+	publishSessionEvent(session, EventRequestVoted, spotifyTrackId)
+
+	return nil
+}
+
+func RemoveVoteFromRequest(session model.SimpleListeningSession, spotifyTrackId string, guest model.Guest) (spotifeteError *SpotifeteError) {
+
+	removeVoteTask := func(tx *gorm.DB) error {
+		var request *model.SongRequest
+		request, spotifeteError = findRequestToVoteOnInTransaction(session, spotifyTrackId, tx)
+		if spotifeteError != nil {
+			return errors.New("rolling back transaction")
+		}
+
+		result := tx.Unscoped().Where(model.SongRequestVote{SongRequestId: request.ID, GuestId: guest.ID}).Delete(&model.SongRequestVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			spotifeteError = NewUserError("You did not vote for this request.")
+			return errors.New("rolling back transaction")
+		}
+
+		return updateVoteScoreInTransaction(*request, tx)
+	}
+
+	err := database.GetConnection().Transaction(removeVoteTask)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+	if err != nil {
+		return NewInternalError("could not remove vote", err)
+	}
+
+	publishSessionEvent(session, EventRequestVoted, spotifyTrackId)
+
+	return nil
+}
+
+func findRequestToVoteOnInTransaction(session model.SimpleListeningSession, spotifyTrackId string, tx *gorm.DB) (*model.SongRequest, *SpotifeteError) {
+
+	request, err := findQueuedRequestForTrackInTransaction(session, spotifyTrackId, tx)
+	if err != nil {
+		return nil, NewInternalError("Could not fetch request to vote on", err)
+	}
+	if request == nil {
+		return nil, NewUserError("Request not found in queue.")
+	}
+
+	return request, nil
+}
+
+// Requesting a track that is already in the queue counts as an upvote for the existing request
+func voteForDuplicateRequestInTransaction(duplicateRequest model.SongRequest, guest model.Guest, tx *gorm.DB) (model.SongRequest, *SpotifeteError) {
+
+	if isRequestedBy(duplicateRequest, guest) {
+		return model.SongRequest{}, NewUserError("You already requested this track.")
+	}
+
+	return voteForRequestInTransaction(duplicateRequest, guest, Upvote, tx)
+}
+
+func voteForRequestInTransaction(request model.SongRequest, guest model.Guest, value int64, tx *gorm.DB) (model.SongRequest, *SpotifeteError) {
+
+	if request.Locked {
+		return model.SongRequest{}, NewUserError("This track is up next and can not be voted on anymore.")
+	}
+
+	if isRequestedBy(request, guest) {
+		return model.SongRequest{}, NewUserError("You can not vote for your own request.")
+	}
+
+	var existingVotes []model.SongRequestVote
+	err := tx.Where(model.SongRequestVote{SongRequestId: request.ID, GuestId: guest.ID}).Find(&existingVotes).Error
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not fetch existing votes from database", err)
+	}
+
+	if len(existingVotes) > 0 {
+		existingVote := existingVotes[0]
+		if existingVote.Value == value {
+			return model.SongRequest{}, NewUserError("You already voted for this request.")
+		}
+
+		existingVote.Value = value
+		err = tx.Save(&existingVote).Error
+	} else {
+		err = tx.Create(&model.SongRequestVote{
+			SongRequestId: request.ID,
+			GuestId:       guest.ID,
+			Value:         value,
+		}).Error
+	}
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not save vote", err)
+	}
+
+	err = updateVoteScoreInTransaction(request, tx)
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not update vote score", err)
+	}
+
+	err = tx.First(&request, request.ID).Error
+	if err != nil {
+		return model.SongRequest{}, NewInternalError("Could not reload request", err)
+	}
+
+	return request, nil
+}
+
+func updateVoteScoreInTransaction(request model.SongRequest, tx *gorm.DB) error {
+
+	voteScore := tx.Model(&model.SongRequestVote{}).Select("coalesce(sum(value), 0)").Where("song_request_id = ?", request.ID)
+	return tx.Model(&request).Update("vote_score", voteScore).Error
+}
+
+func isRequestedBy(request model.SongRequest, guest model.Guest) bool {
+	return request.GuestId != nil && *request.GuestId == guest.ID
+}
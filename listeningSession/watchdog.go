@@ -0,0 +1,163 @@
+package listeningSession
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/logger"
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/zmb3/spotify"
+)
+
+const (
+	PlaybackStateUnknown               = "UNKNOWN"
+	PlaybackStatePlayingSession        = "PLAYING_SESSION"
+	PlaybackStatePaused                = "PAUSED"
+	PlaybackStatePlayingForeignContext = "PLAYING_FOREIGN_CONTEXT"
+	PlaybackStateIdle                  = "IDLE"
+	PlaybackStateNoDevice              = "NO_DEVICE"
+)
+
+var playbackStateDescriptions = map[string]string{
+	PlaybackStateUnknown:               "The playback has not been checked yet.",
+	PlaybackStatePlayingSession:        "Everything is fine, the session is playing.",
+	PlaybackStatePaused:                "Playback of the session is paused.",
+	PlaybackStatePlayingForeignContext: "Spotify is playing something other than the session playlist.",
+	PlaybackStateIdle:                  "Nothing is playing. Start playback of the session to continue.",
+	PlaybackStateNoDevice:              "No Spotify device is available. Open Spotify on any device to continue.",
+}
+
+func DescribePlaybackState(playbackState string) string {
+	description, known := playbackStateDescriptions[playbackState]
+	if !known {
+		return playbackStateDescriptions[PlaybackStateUnknown]
+	}
+
+	return description
+}
+
+func SetAutoRepairPlayback(session model.SimpleListeningSession, user model.SimpleUser, autoRepair bool) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can change the playback repair mode.")
+	}
+
+	if autoRepair == session.AutoRepairPlayback {
+		return nil
+	}
+
+	session.AutoRepairPlayback = autoRepair
+	database.GetConnection().Model(&session).Update("auto_repair_playback", autoRepair)
+
+	return nil
+}
+
+// Records what the owner's player is doing and restarts the queue if the owner allowed it
+func watchPlayback(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) {
+
+	playbackState, activeDevice := classifyPlayback(session, queue, currentlyPlaying)
+	updatePlaybackState(&session.SimpleListeningSession, playbackState)
+
+	if session.AutoRepairPlayback && len(queue) > 0 && shouldRepairPlayback(playbackState) {
+		if hasFinishedFirstRequest(session, queue, currentlyPlaying) {
+			var spotifeteError *SpotifeteError
+			queue, spotifeteError = advanceQueue(session, queue)
+			if spotifeteError != nil {
+				return
+			}
+
+			if len(queue) == 0 {
+				// Syncing the playback leaves the queue playlist alone if nothing is queued, so the finished track has to be removed here
+				updatePlaylist(session, queue)
+				return
+			}
+		}
+
+		logger.Info(fmt.Sprintf("Restarting playback for session %d (playback state was %s).", session.ID, playbackState))
+
+		spotifeteError := playbackBackendForSession(session.SimpleListeningSession).play(session, queue, activeDevice)
+		if spotifeteError == nil {
+			updatePlaybackState(&session.SimpleListeningSession, PlaybackStatePlayingSession)
+		}
+	}
+}
+
+// A pause is left alone, because the owner probably paused on purpose
+func shouldRepairPlayback(playbackState string) bool {
+	return playbackState == PlaybackStateIdle || playbackState == PlaybackStatePlayingForeignContext
+}
+
+// Also returns the device the queue should be played on if it has to be restarted. It is nil if Spotify should use the active device.
+func classifyPlayback(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) (playbackState string, device *spotify.ID) {
+
+	// Spotify does not return anything when there is no active device
+	if currentlyPlaying.Item == nil && !currentlyPlaying.Playing {
+		return classifyPlaybackWithoutActiveDevice(session)
+	}
+
+	playingSession := isPlayingSession(session, queue, currentlyPlaying)
+	if currentlyPlaying.Playing {
+		if playingSession {
+			return PlaybackStatePlayingSession, nil
+		}
+		return PlaybackStatePlayingForeignContext, nil
+	}
+
+	// When Spotify reaches the end of the queue, it stops and jumps back to the start of the track
+	if playingSession && currentlyPlaying.Progress > 0 {
+		return PlaybackStatePaused, nil
+	}
+
+	return PlaybackStateIdle, nil
+}
+
+// When Spotify reaches the end of the queue, it stops and jumps back to the start of the last track.
+// Restarting the queue would play that track again, because the queue only advances once the next track starts.
+func hasFinishedFirstRequest(session model.FullListeningSession, queue []model.SongRequest, currentlyPlaying spotify.CurrentlyPlaying) bool {
+
+	if len(queue) == 0 || currentlyPlaying.Item == nil || currentlyPlaying.Playing || currentlyPlaying.Progress > 0 {
+		return false
+	}
+
+	return currentlyPlaying.Item.ID.String() == queue[0].SpotifyTrackId && isPlayingSession(session, queue, currentlyPlaying)
+}
+
+func classifyPlaybackWithoutActiveDevice(session model.FullListeningSession) (playbackState string, device *spotify.ID) {
+
+	devices, err := Client(session).PlayerDevices()
+	if err != nil {
+		// Owners who logged in before the playback state scope was requested can not list their devices
+		return PlaybackStateIdle, nil
+	}
+
+	for _, availableDevice := range devices {
+		if !availableDevice.Restricted {
+			deviceId := availableDevice.ID
+			return PlaybackStateIdle, &deviceId
+		}
+	}
+
+	return PlaybackStateNoDevice, nil
+}
+
+// Only writes changes, and without touching updated_at, so a silent session still times out
+func updatePlaybackState(session *model.SimpleListeningSession, playbackState string) {
+
+	if session.PlaybackState == playbackState {
+		return
+	}
+
+	now := time.Now()
+	err := database.GetConnection().Model(session).UpdateColumns(map[string]interface{}{
+		"playback_state":       playbackState,
+		"playback_state_since": now,
+	}).Error
+	if err != nil {
+		NewInternalError("could not save playback state", err)
+		return
+	}
+
+	session.PlaybackState = playbackState
+	session.PlaybackStateSince = &now
+}
@@ -0,0 +1,73 @@
+package listeningSession
+
+import (
+	"testing"
+
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/zmb3/spotify"
+)
+
+func watchedSession() model.FullListeningSession {
+	return model.FullListeningSession{
+		SimpleListeningSession: model.SimpleListeningSession{QueuePlaylistId: "queuePlaylist"},
+	}
+}
+
+func currentlyPlayingTrack(trackId string, contextUri spotify.URI, playing bool, progressMs int) spotify.CurrentlyPlaying {
+	return spotify.CurrentlyPlaying{
+		PlaybackContext: spotify.PlaybackContext{Type: "playlist", URI: contextUri},
+		Progress:        progressMs,
+		Playing:         playing,
+		Item:            &spotify.FullTrack{SimpleTrack: spotify.SimpleTrack{ID: spotify.ID(trackId)}},
+	}
+}
+
+func TestClassifyPlayback(t *testing.T) {
+
+	queue := []model.SongRequest{queuedRequest("track1", 180000)}
+
+	tests := []struct {
+		name             string
+		currentlyPlaying spotify.CurrentlyPlaying
+		want             string
+	}{
+		{"playing session", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", true, 60000), PlaybackStatePlayingSession},
+		{"playing other playlist", currentlyPlayingTrack("other", "spotify:playlist:other", true, 60000), PlaybackStatePlayingForeignContext},
+		{"paused session", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", false, 60000), PlaybackStatePaused},
+		{"finished last track", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", false, 0), PlaybackStateIdle},
+		{"stopped other playlist", currentlyPlayingTrack("other", "spotify:playlist:other", false, 60000), PlaybackStateIdle},
+	}
+
+	for _, test := range tests {
+		if got, _ := classifyPlayback(watchedSession(), queue, test.currentlyPlaying); got != test.want {
+			t.Errorf("%s: playback state is %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestHasFinishedFirstRequest(t *testing.T) {
+
+	queue := []model.SongRequest{queuedRequest("track1", 180000), queuedRequest("track2", 180000)}
+
+	tests := []struct {
+		name             string
+		currentlyPlaying spotify.CurrentlyPlaying
+		want             bool
+	}{
+		{"stopped at start of first request", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", false, 0), true},
+		{"first request is playing", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", true, 0), false},
+		{"first request is paused", currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", false, 60000), false},
+		{"stopped at start of other request", currentlyPlayingTrack("track2", "spotify:playlist:queuePlaylist", false, 0), false},
+		{"stopped in other playlist", currentlyPlayingTrack("track1", "spotify:playlist:other", false, 0), false},
+	}
+
+	for _, test := range tests {
+		if got := hasFinishedFirstRequest(watchedSession(), queue, test.currentlyPlaying); got != test.want {
+			t.Errorf("%s: finished first request is %t, want %t", test.name, got, test.want)
+		}
+	}
+
+	if hasFinishedFirstRequest(watchedSession(), nil, currentlyPlayingTrack("track1", "spotify:playlist:queuePlaylist", false, 0)) {
+		t.Errorf("empty queue has a finished first request")
+	}
+}
@@ -10,8 +10,6 @@ import (
 	"github.com/partyoffice/spotifete/config"
 )
 
-var logDirectory = config.Get().SpotifeteConfiguration.LogDirectory
-
 func SetupLogging() {
 	setupSpotifeteLog()
 	setupSentryLog()
@@ -19,7 +17,7 @@ func SetupLogging() {
 }
 
 func OpenLogFile(logFileName string) *os.File {
-	logFilePath := filepath.Join(logDirectory, logFileName)
+	logFilePath := filepath.Join(config.Get().SpotifeteConfiguration.LogDirectory, logFileName)
 
 	err := os.MkdirAll(filepath.Dir(logFilePath), os.ModePerm)
 	if err != nil {
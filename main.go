@@ -5,9 +5,11 @@ import (
 	"os"
 
 	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/jobs"
 	"github.com/partyoffice/spotifete/listeningSession"
 	"github.com/partyoffice/spotifete/logging"
 	"github.com/partyoffice/spotifete/webapp"
+	"github.com/partyoffice/spotifete/webhooks"
 )
 
 var spotifeteWebapp webapp.SpotifeteWebapp
@@ -31,6 +33,8 @@ func printBanner() {
 func setup() {
 	logging.SetupLogging()
 	database.GetConnection()
+	listeningSession.Setup()
+	webhooks.Setup()
 	setupWebapp()
 }
 
@@ -40,5 +44,6 @@ func setupWebapp() {
 
 func run() {
 	listeningSession.StartPollSessionsLoop()
+	jobs.StartWorkers()
 	spotifeteWebapp.Run()
 }
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE song_requests
+    DROP COLUMN vote_score;
+
+DROP TABLE song_request_votes;
+
+COMMIT;
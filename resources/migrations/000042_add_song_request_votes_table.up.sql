@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE song_request_votes (
+    id              SERIAL PRIMARY KEY,
+    created_at      TIMESTAMP WITH TIME ZONE,
+    updated_at      TIMESTAMP WITH TIME ZONE,
+    deleted_at      TIMESTAMP WITH TIME ZONE,
+    song_request_id INTEGER     NOT NULL REFERENCES song_requests (id),
+    voter           VARCHAR(63) NOT NULL,
+    value           SMALLINT    NOT NULL CHECK (value IN (-1, 1))
+);
+
+-- Every voter can only vote once per request
+CREATE UNIQUE INDEX song_request_votes_song_request_id_voter_index
+    ON song_request_votes (song_request_id, voter);
+
+ALTER TABLE song_requests
+    ADD COLUMN vote_score INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN queue_strategy;
+
+COMMIT;
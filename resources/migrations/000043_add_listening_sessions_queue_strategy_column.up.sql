@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    ADD COLUMN queue_strategy VARCHAR NOT NULL DEFAULT 'WEIGHTED_FAIRNESS';
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN max_unplayed_requests,
+    DROP COLUMN max_requests_per_window,
+    DROP COLUMN request_window_minutes,
+    DROP COLUMN min_seconds_between_requests;
+
+COMMIT;
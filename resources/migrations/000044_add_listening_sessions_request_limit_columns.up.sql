@@ -0,0 +1,10 @@
+BEGIN;
+
+-- A limit of 0 means unlimited
+ALTER TABLE listening_sessions
+    ADD COLUMN max_unplayed_requests        INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN max_requests_per_window      INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN request_window_minutes       INTEGER NOT NULL DEFAULT 60,
+    ADD COLUMN min_seconds_between_requests INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+DELETE
+FROM song_request_votes;
+
+UPDATE song_requests
+SET vote_score = 0;
+
+ALTER TABLE song_request_votes
+    DROP COLUMN guest_id,
+    ADD COLUMN voter VARCHAR(63) NOT NULL;
+
+CREATE UNIQUE INDEX song_request_votes_song_request_id_voter_index
+    ON song_request_votes (song_request_id, voter);
+
+ALTER TABLE song_requests
+    DROP COLUMN guest_id;
+
+DROP TABLE guests;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE guests (
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP WITH TIME ZONE,
+    updated_at   TIMESTAMP WITH TIME ZONE,
+    deleted_at   TIMESTAMP WITH TIME ZONE,
+    session_id   INTEGER     NOT NULL REFERENCES listening_sessions (id),
+    display_name VARCHAR(63) NOT NULL
+);
+
+CREATE INDEX guests_session_id_index
+    ON guests (session_id);
+
+ALTER TABLE song_requests
+    ADD COLUMN guest_id INTEGER REFERENCES guests (id);
+
+CREATE INDEX song_requests_session_id_guest_id_index
+    ON song_requests (session_id, guest_id);
+
+-- Votes were keyed by free-text usernames that can not be mapped to guests, so they are dropped
+DELETE
+FROM song_request_votes;
+
+UPDATE song_requests
+SET vote_score = 0;
+
+ALTER TABLE song_request_votes
+    DROP COLUMN voter,
+    ADD COLUMN guest_id INTEGER NOT NULL REFERENCES guests (id);
+
+CREATE UNIQUE INDEX song_request_votes_song_request_id_guest_id_index
+    ON song_request_votes (song_request_id, guest_id);
+
+COMMIT;
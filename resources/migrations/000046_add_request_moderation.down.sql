@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX song_requests_session_id_approval_status_index;
+
+ALTER TABLE song_requests
+    DROP COLUMN approval_status,
+    DROP COLUMN rejection_reason;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN moderated;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    ADD COLUMN moderated BOOLEAN NOT NULL DEFAULT false;
+
+-- Requests in moderated sessions stay PENDING until the owner approves or rejects them
+ALTER TABLE song_requests
+    ADD COLUMN approval_status  VARCHAR(15) NOT NULL DEFAULT 'APPROVED'
+        CHECK (approval_status IN ('APPROVED', 'PENDING', 'REJECTED')),
+    ADD COLUMN rejection_reason VARCHAR(255) NULL;
+
+CREATE INDEX song_requests_session_id_approval_status_index
+    ON song_requests (session_id, approval_status);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE session_rules (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    session_id INTEGER      NOT NULL REFERENCES listening_sessions (id),
+    rule_type  VARCHAR(15)  NOT NULL CHECK (rule_type IN ('BLOCK', 'ALLOW')),
+    subject    VARCHAR(15)  NOT NULL CHECK (subject IN ('TRACK', 'ARTIST', 'ALBUM')),
+    spotify_id VARCHAR(255) NOT NULL,
+    name       VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX session_rules_session_id_rule_type_subject_spotify_id_index
+    ON session_rules (session_id, rule_type, subject, spotify_id);
+
+COMMIT;
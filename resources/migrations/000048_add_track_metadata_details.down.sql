@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX track_metadata_isrc_index;
+
+ALTER TABLE track_metadata
+    DROP COLUMN duration_ms,
+    DROP COLUMN explicit,
+    DROP COLUMN popularity,
+    DROP COLUMN isrc,
+    DROP COLUMN spotify_artist_ids,
+    DROP COLUMN spotify_album_id,
+    DROP COLUMN preview_url;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE track_metadata
+    ADD COLUMN duration_ms        INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN explicit           BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN popularity         INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN isrc               VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN spotify_artist_ids VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN spotify_album_id   VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN preview_url        VARCHAR NOT NULL DEFAULT '';
+
+CREATE INDEX track_metadata_isrc_index
+    ON track_metadata (isrc);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN allow_explicit,
+    DROP COLUMN max_track_duration_seconds;
+
+COMMIT;
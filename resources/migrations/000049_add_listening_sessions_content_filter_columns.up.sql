@@ -0,0 +1,8 @@
+BEGIN;
+
+-- A maximum duration of 0 means unlimited
+ALTER TABLE listening_sessions
+    ADD COLUMN allow_explicit             BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN max_track_duration_seconds INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
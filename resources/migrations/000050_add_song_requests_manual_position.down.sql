@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE song_requests
+    DROP COLUMN manual_position;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Set when the session owner moves requests around, takes precedence over the queue strategy
+ALTER TABLE song_requests
+    ADD COLUMN manual_position INTEGER NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN playback_state,
+    DROP COLUMN playback_state_since,
+    DROP COLUMN auto_repair_playback;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    ADD COLUMN playback_state       VARCHAR(31) NOT NULL DEFAULT 'UNKNOWN',
+    ADD COLUMN playback_state_since TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN auto_repair_playback BOOLEAN     NOT NULL DEFAULT false;
+
+COMMIT;
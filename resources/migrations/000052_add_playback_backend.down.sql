@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE song_requests
+    DROP COLUMN pushed_to_player;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN playback_backend;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE listening_sessions
+    ADD COLUMN playback_backend VARCHAR NOT NULL DEFAULT 'PLAYLIST';
+
+-- Tracks in the native Spotify queue can not be read or removed, so we remember which requests were already added
+ALTER TABLE song_requests
+    ADD COLUMN pushed_to_player BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Number of requests that are locked at the start of the queue and synced to the player, including the one currently playing
+ALTER TABLE listening_sessions
+    ADD COLUMN queue_lookahead INTEGER NOT NULL DEFAULT 2 CHECK (queue_lookahead BETWEEN 2 AND 10);
+
+COMMIT;
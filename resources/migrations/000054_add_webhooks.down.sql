@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhooks;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+-- Events are stored comma separated
+CREATE TABLE webhooks (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    session_id INTEGER       NOT NULL REFERENCES listening_sessions (id),
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(64)   NOT NULL,
+    events     VARCHAR(255)  NOT NULL
+);
+
+CREATE INDEX webhooks_session_id_index
+    ON webhooks (session_id);
+
+CREATE TABLE webhook_deliveries (
+    id              SERIAL PRIMARY KEY,
+    created_at      TIMESTAMP WITH TIME ZONE,
+    updated_at      TIMESTAMP WITH TIME ZONE,
+    deleted_at      TIMESTAMP WITH TIME ZONE,
+    webhook_id      INTEGER     NOT NULL REFERENCES webhooks (id),
+    event           VARCHAR(31) NOT NULL,
+    payload         TEXT        NOT NULL,
+    status          VARCHAR(15) NOT NULL CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    error           TEXT,
+    delivered_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_created_at_index
+    ON webhook_deliveries (webhook_id, created_at);
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+-- Jobs that keep failing are kept with status DEAD, so they can be inspected and retried by hand
+CREATE TABLE jobs (
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP WITH TIME ZONE,
+    updated_at   TIMESTAMP WITH TIME ZONE,
+    deleted_at   TIMESTAMP WITH TIME ZONE,
+    job_type     VARCHAR(63) NOT NULL,
+    payload      TEXT        NOT NULL,
+    status       VARCHAR(15) NOT NULL CHECK (status IN ('PENDING', 'RUNNING', 'DEAD')),
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    max_attempts INTEGER     NOT NULL,
+    run_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error   TEXT
+);
+
+CREATE INDEX jobs_status_run_at_index
+    ON jobs (status, run_at);
+
+COMMIT;
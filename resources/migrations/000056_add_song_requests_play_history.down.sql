@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX song_requests_session_id_played_at_index;
+
+ALTER TABLE song_requests
+    DROP COLUMN played_at,
+    DROP COLUMN from_fallback;
+
+COMMIT;
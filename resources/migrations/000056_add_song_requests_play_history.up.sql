@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE song_requests
+    ADD COLUMN played_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN from_fallback BOOLEAN NOT NULL DEFAULT false;
+
+-- The exact time is unknown for requests played before, but the last update is usually the moment the queue advanced
+UPDATE song_requests
+SET played_at = updated_at
+WHERE played = true;
+
+UPDATE song_requests
+SET from_fallback = true
+WHERE guest_id IS NULL
+  AND requested_by = 'Fallback-Playlist';
+
+CREATE INDEX song_requests_session_id_played_at_index
+    ON song_requests (session_id, played_at);
+
+COMMIT;
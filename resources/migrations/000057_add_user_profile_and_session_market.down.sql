@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE users
+    DROP COLUMN product,
+    DROP COLUMN profile_refreshed_at;
+
+ALTER TABLE listening_sessions
+    DROP COLUMN market;
+
+COMMIT;
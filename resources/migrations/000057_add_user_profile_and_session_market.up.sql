@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Profile data is refreshed from Spotify periodically instead of on every request
+ALTER TABLE users
+    ADD COLUMN product VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN profile_refreshed_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- NULL means the country of the owner is used
+ALTER TABLE listening_sessions
+    ADD COLUMN market VARCHAR(2) NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE track_metadata
+    DROP COLUMN checked_market,
+    DROP COLUMN playable;
+
+COMMIT;
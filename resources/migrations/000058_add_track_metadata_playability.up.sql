@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Metadata saved before has no known playability, so it is fetched from Spotify again on the next request
+ALTER TABLE track_metadata
+    ADD COLUMN checked_market VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN playable BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DELETE FROM jobs
+WHERE job_type = 'REFRESH_LEGACY_TRACK_METADATA';
+
+COMMIT;
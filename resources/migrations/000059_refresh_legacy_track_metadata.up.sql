@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Track metadata saved before migration 48 has no duration, so start times of queued requests can not be estimated.
+-- The job fetches the metadata of queued tracks again, other tracks are updated once they are requested again.
+INSERT INTO jobs (created_at, updated_at, job_type, payload, status, max_attempts, run_at)
+VALUES (now(), now(), 'REFRESH_LEGACY_TRACK_METADATA', '{}', 'PENDING', 8, now());
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Remembers the rewind playlist once it was created, so a retry of the job fills the same playlist instead of creating another one
+ALTER TABLE listening_sessions
+    ADD COLUMN rewind_playlist VARCHAR NULL;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DELETE FROM jobs
+WHERE job_type = 'DELIVER_WEBHOOK';
+
+COMMIT;
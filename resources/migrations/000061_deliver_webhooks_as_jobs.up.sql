@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Webhook deliveries used to be retried in memory only, so deliveries that were pending during a restart were never sent
+INSERT INTO jobs (created_at, updated_at, job_type, payload, status, max_attempts, run_at)
+SELECT now(), now(), 'DELIVER_WEBHOOK', json_build_object('delivery_id', id)::text, 'PENDING', 8, now()
+FROM webhook_deliveries
+WHERE status = 'PENDING'
+  AND deleted_at IS NULL;
+
+COMMIT;
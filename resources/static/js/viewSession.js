@@ -1,11 +1,9 @@
 let currentSessionJoinId;
-let queueLastUpdated;
 
 $(document).ready(function () {
     currentSessionJoinId = $('#currentSessionJoinId').val();
-    queueLastUpdated = $('#queueLastUpdated').val();
 
-    pollQueueLastUpdated();
+    listenForSessionEvents();
 
     // Constructing the suggestion engine
     var engine = new Bloodhound({
@@ -43,12 +41,24 @@ $(document).ready(function () {
             },
             templates: {
                 suggestion: function (suggestionData) {
+                    if (suggestionData.blocked) {
+                        return `<div class="text-muted" title="${suggestionData.blocked_reason}">
+                                    <div class="media">
+                                        <img src="${suggestionData.album_image_thumbnail_url}" class="mr-3" alt="${suggestionData.album_name}">
+                                        <div class="media-body">
+                                            <h5 class="mt-0">${suggestionData.track_name} ${explicitBadge(suggestionData)} <span class="badge badge-secondary">Not allowed</span></h5>
+                                            <p>${suggestionData.artist_name} - ${suggestionData.album_name}</p>
+                                        </div>
+                                    </div>
+                                </div>`;
+                    }
+
                     return `<div class="clickable" onclick="requestTrack('${suggestionData.spotify_track_id}')">
                                 <div class="media">
                                     <img src="${suggestionData.album_image_thumbnail_url}" class="mr-3" alt="${suggestionData.album_name}">
                                     <div class="media-body">
-                                        <h5 class="mt-0">${suggestionData.track_name}</h5>
-                                        <p>${suggestionData.artist_name} - ${suggestionData.album_name}</p>
+                                        <h5 class="mt-0">${suggestionData.track_name} ${explicitBadge(suggestionData)}</h5>
+                                        <p>${suggestionData.artist_name} - ${suggestionData.album_name} <small class="text-muted">${formatDuration(suggestionData.duration_ms)}</small></p>
                                     </div>
                                 </div>
                             </div>`;
@@ -69,25 +79,53 @@ $(document).ready(function () {
     trackSearchInput.focus();
 });
 
+function explicitBadge(track) {
+    return track.explicit ? '<span class="badge badge-secondary" title="explicit">E</span>' : '';
+}
+
+function formatDuration(durationMs) {
+    if (!durationMs) {
+        return '';
+    }
+
+    const totalSeconds = Math.floor(durationMs / 1000);
+    const seconds = totalSeconds % 60;
+    return `${Math.floor(totalSeconds / 60)}:${seconds < 10 ? '0' : ''}${seconds}`;
+}
+
 function requestTrack(trackId) {
     $('#requestTrackIdInput').val(trackId);
     $('#submitRequestForm').submit();
 }
 
-function pollQueueLastUpdated() {
-    $.ajax({
-        url: `/api/v2/session/id/${$('#currentSessionJoinId').val()}/queue/last-updated`
-    }).done(function(data) {
-        if (Date.parse(data.queue_last_updated) > Date.parse(queueLastUpdated)) {
+function listenForSessionEvents() {
+    const events = new EventSource(`/api/v2/session/id/${currentSessionJoinId}/events`);
+    const renderedQueueLastUpdated = parseInt($('#queueLastUpdated').val());
+
+    // Sent on every (re)connect, so changes that happened while no connection was open are not missed
+    events.addEventListener('QUEUE_STATE', function (event) {
+        if (JSON.parse(event.data).queue_last_updated_ms > renderedQueueLastUpdated) {
+            events.close();
             location.reload();
-        } else {
-            setTimeout(function(){
-                pollQueueLastUpdated();
-            }, 2000);
         }
-    }).fail(function () {
-        setTimeout(function(){
-            pollQueueLastUpdated();
-        }, 2000);
+    });
+
+    // The page is rendered on the server, so it is simply reloaded whenever something changes
+    [
+        'REQUEST_CREATED',
+        'REQUEST_DELETED',
+        'REQUEST_VOTED',
+        'REQUEST_LOCKED',
+        'REQUEST_APPROVED',
+        'REQUEST_REJECTED',
+        'QUEUE_REORDERED',
+        'QUEUE_ADVANCED',
+        'FALLBACK_PLAYLIST_CHANGED',
+        'SESSION_CLOSED'
+    ].forEach(function (eventType) {
+        events.addEventListener(eventType, function () {
+            events.close();
+            location.reload();
+        });
     });
 }
@@ -1,11 +1,15 @@
 package shared
 
 import (
+	"errors"
 	"github.com/getsentry/sentry-go"
 	"github.com/google/logger"
 	"net/http"
 )
 
+// Spotify calls fail with an error wrapping this one if Spotify keeps rejecting requests because we sent too many
+var ErrSpotifyThrottled = errors.New("spotify is throttling requests")
+
 type SpotifeteError struct {
 	MessageForUser string
 	HttpStatus     int
@@ -16,6 +20,10 @@ type SpotifeteError struct {
 //
 // This is useful for most cases, where the reason might be interesting for the user
 func NewError(message string, cause error, httpStatus int) *SpotifeteError {
+	if errors.Is(cause, ErrSpotifyThrottled) {
+		return newThrottledError(message, cause)
+	}
+
 	defer logErrorAsync(message, cause, 1)
 
 	return &SpotifeteError{
@@ -40,6 +48,10 @@ func NewUserError(message string) *SpotifeteError {
 //
 // This is useful if you don't want the user to see the details of the error
 func NewInternalError(message string, cause error) *SpotifeteError {
+	if errors.Is(cause, ErrSpotifyThrottled) {
+		return newThrottledError(message, cause)
+	}
+
 	defer logErrorAsync(message, cause, 1)
 
 	return &SpotifeteError{
@@ -48,6 +60,17 @@ func NewInternalError(message string, cause error) *SpotifeteError {
 	}
 }
 
+// Creates an error struct telling the user to try again later and http status 503 (Service Unavailable)
+// The message and cause will only be logged as a warning, because there is nothing to fix when Spotify throttles us
+func newThrottledError(message string, cause error) *SpotifeteError {
+	logger.WarningDepth(2, buildMessageWithCause(message, cause))
+
+	return &SpotifeteError{
+		MessageForUser: "Spotify is receiving too many requests right now. Please try again in a few seconds.",
+		HttpStatus:     http.StatusServiceUnavailable,
+	}
+}
+
 func logErrorAsync(message string, cause error, logDepth int) {
 	logError(message, cause, logDepth+1)
 }
@@ -0,0 +1,45 @@
+package spotifyApi
+
+import (
+	"io"
+
+	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
+)
+
+// The part of the Spotify Web API SpotiFete uses. Everything that talks to Spotify should go through this interface,
+// so it can be pointed at the fake API in package fake instead of the real one.
+type Client interface {
+	Token() (*oauth2.Token, error)
+	CurrentUser() (*spotify.PrivateUser, error)
+
+	SearchOpt(query string, searchType spotify.SearchType, opt *spotify.Options) (*spotify.SearchResult, error)
+
+	GetTrack(id spotify.ID) (*spotify.FullTrack, error)
+	GetTrackOpt(id spotify.ID, opt *spotify.Options) (*spotify.FullTrack, error)
+	GetAlbum(id spotify.ID) (*spotify.FullAlbum, error)
+	GetArtist(id spotify.ID) (*spotify.FullArtist, error)
+
+	GetPlaylist(playlistId spotify.ID) (*spotify.FullPlaylist, error)
+	GetPlaylistTracksOpt(playlistId spotify.ID, opt *spotify.Options, fields string) (*spotify.PlaylistTrackPage, error)
+	CreatePlaylistForUser(userId, playlistName, description string, public bool) (*spotify.FullPlaylist, error)
+	AddTracksToPlaylist(playlistId spotify.ID, trackIds ...spotify.ID) (snapshotId string, err error)
+	ReplacePlaylistTracks(playlistId spotify.ID, trackIds ...spotify.ID) error
+	FollowPlaylist(owner spotify.ID, playlist spotify.ID, public bool) error
+	UnfollowPlaylist(owner, playlist spotify.ID) error
+	SetPlaylistImage(playlistId spotify.ID, img io.Reader) error
+
+	PlayerDevices() ([]spotify.PlayerDevice, error)
+	PlayerCurrentlyPlaying() (*spotify.CurrentlyPlaying, error)
+	Play() error
+	PlayOpt(opt *spotify.PlayOptions) error
+	Pause() error
+	Next() error
+	QueueSong(trackId spotify.ID) error
+	Seek(position int) error
+	Volume(percent int) error
+	ShuffleOpt(shuffle bool, opt *spotify.PlayOptions) error
+	RepeatOpt(state string, opt *spotify.PlayOptions) error
+}
+
+var _ Client = (*spotify.Client)(nil)
@@ -0,0 +1,232 @@
+package fake
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zmb3/spotify"
+)
+
+// Builds a track with everything SpotiFete reads from it. The artist and album ids are derived from the track id.
+func NewTrack(id string, name string, artistName string, albumName string, durationMs int) spotify.FullTrack {
+
+	artist := spotify.SimpleArtist{
+		Name: artistName,
+		ID:   spotify.ID(id + "-artist"),
+		URI:  spotify.URI("spotify:artist:" + id + "-artist"),
+	}
+
+	return spotify.FullTrack{
+		SimpleTrack: spotify.SimpleTrack{
+			Artists:  []spotify.SimpleArtist{artist},
+			Duration: durationMs,
+			ID:       spotify.ID(id),
+			Name:     name,
+			URI:      spotify.URI("spotify:track:" + id),
+			Type:     "track",
+		},
+		Album: spotify.SimpleAlbum{
+			Name:    albumName,
+			Artists: []spotify.SimpleArtist{artist},
+			ID:      spotify.ID(id + "-album"),
+			URI:     spotify.URI("spotify:album:" + id + "-album"),
+			Images: []spotify.Image{
+				{Height: 640, Width: 640, URL: "https://i.scdn.co/image/" + id + "-album-large"},
+				{Height: 300, Width: 300, URL: "https://i.scdn.co/image/" + id + "-album-medium"},
+				{Height: 64, Width: 64, URL: "https://i.scdn.co/image/" + id + "-album-small"},
+			},
+		},
+	}
+}
+
+// Adds a track to the catalog. Its artists and album are added too if they are not known yet.
+// A track without available markets is playable everywhere.
+func (server *Server) AddTrack(track spotify.FullTrack) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.tracks[track.ID] = track
+
+	for _, artist := range track.Artists {
+		if _, found := server.artists[artist.ID]; !found {
+			server.artists[artist.ID] = spotify.FullArtist{SimpleArtist: artist}
+		}
+	}
+
+	if _, found := server.albums[track.Album.ID]; !found {
+		server.albums[track.Album.ID] = spotify.FullAlbum{SimpleAlbum: track.Album}
+	}
+}
+
+// Replaces an album, e.g. to give it genres
+func (server *Server) AddAlbum(album spotify.FullAlbum) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.albums[album.ID] = album
+}
+
+// Replaces an artist, e.g. to give it genres
+func (server *Server) AddArtist(artist spotify.FullArtist) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.artists[artist.ID] = artist
+}
+
+// Like the real API, is_playable is only set if a market is given
+func trackForMarket(track spotify.FullTrack, market string) spotify.FullTrack {
+
+	if market == "" {
+		return track
+	}
+
+	playable := len(track.AvailableMarkets) == 0
+	for _, availableMarket := range track.AvailableMarkets {
+		if availableMarket == market {
+			playable = true
+		}
+	}
+	track.IsPlayable = &playable
+
+	return track
+}
+
+func (server *Server) getTrack(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	track, found := server.tracks[spotify.ID(r.PathValue("id"))]
+	if !found {
+		writeError(w, http.StatusNotFound, "non existing id")
+		return
+	}
+
+	writeJson(w, http.StatusOK, trackForMarket(track, r.URL.Query().Get("market")))
+}
+
+func (server *Server) getAlbum(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	album, found := server.albums[spotify.ID(r.PathValue("id"))]
+	if !found {
+		writeError(w, http.StatusNotFound, "non existing id")
+		return
+	}
+
+	writeJson(w, http.StatusOK, album)
+}
+
+func (server *Server) getArtist(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	artist, found := server.artists[spotify.ID(r.PathValue("id"))]
+	if !found {
+		writeError(w, http.StatusNotFound, "non existing id")
+		return
+	}
+
+	writeJson(w, http.StatusOK, artist)
+}
+
+// Matches tracks by name, artist and album and playlists by name. Wildcards are ignored.
+func (server *Server) search(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	query := r.URL.Query()
+	searchTerm := strings.ToLower(strings.Trim(query.Get("q"), " *"))
+	market := query.Get("market")
+	limit, offset := pagingParameters(query.Get("limit"), query.Get("offset"), 20)
+
+	result := spotify.SearchResult{}
+	for _, searchType := range strings.Split(query.Get("type"), ",") {
+		switch searchType {
+		case "track":
+			var matchingTracks []spotify.FullTrack
+			for _, track := range server.tracks {
+				if trackMatches(track, searchTerm) {
+					matchingTracks = append(matchingTracks, trackForMarket(track, market))
+				}
+			}
+			sortTracks(matchingTracks)
+
+			page := spotify.FullTrackPage{Tracks: pageOf(matchingTracks, limit, offset)}
+			page.Limit, page.Offset, page.Total = limit, offset, len(matchingTracks)
+			result.Tracks = &page
+		case "playlist":
+			var matchingPlaylists []spotify.SimplePlaylist
+			for _, playlist := range server.playlists {
+				if strings.Contains(strings.ToLower(playlist.Name), searchTerm) {
+					matchingPlaylists = append(matchingPlaylists, server.simplePlaylist(playlist))
+				}
+			}
+			sortPlaylists(matchingPlaylists)
+
+			page := spotify.SimplePlaylistPage{Playlists: pageOf(matchingPlaylists, limit, offset)}
+			page.Limit, page.Offset, page.Total = limit, offset, len(matchingPlaylists)
+			result.Playlists = &page
+		default:
+			writeError(w, http.StatusBadRequest, "Bad search type field "+searchType)
+			return
+		}
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+// Maps have no order, so results are sorted by id to be stable
+func sortTracks(tracks []spotify.FullTrack) {
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].ID < tracks[j].ID
+	})
+}
+
+func sortPlaylists(playlists []spotify.SimplePlaylist) {
+	sort.Slice(playlists, func(i, j int) bool {
+		return playlists[i].ID < playlists[j].ID
+	})
+}
+
+func trackMatches(track spotify.FullTrack, searchTerm string) bool {
+
+	if strings.Contains(strings.ToLower(track.Name), searchTerm) || strings.Contains(strings.ToLower(track.Album.Name), searchTerm) {
+		return true
+	}
+
+	for _, artist := range track.Artists {
+		if strings.Contains(strings.ToLower(artist.Name), searchTerm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pagingParameters(limitParameter string, offsetParameter string, defaultLimit int) (limit int, offset int) {
+
+	limit, err := strconv.Atoi(limitParameter)
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+
+	offset, err = strconv.Atoi(offsetParameter)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+func pageOf[T any](items []T, limit int, offset int) []T {
+
+	if offset >= len(items) {
+		return []T{}
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end]
+}
@@ -0,0 +1,436 @@
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zmb3/spotify"
+)
+
+// The simulated player of a user. Time passes for the progress of the current track, but a track never ends on its own,
+// call FinishTrack for that.
+type Player struct {
+	Devices        []spotify.PlayerDevice
+	ActiveDeviceId spotify.ID
+	IsPlaying      bool
+	// The playlist that is played, empty if the tracks were started directly
+	ContextUri spotify.URI
+	// The tracks that were started directly. The tracks of a playlist context are always read from the playlist itself.
+	Tracks []spotify.ID
+	// Position of the current track in the context or the directly started tracks
+	Position int
+	// Tracks added with QueueSong. They are played before the context continues.
+	Queue         []spotify.ID
+	CurrentTrack  spotify.ID
+	ProgressMs    int
+	Shuffle       bool
+	Repeat        string
+	VolumePercent int
+
+	// When the player was started or resumed the last time, ProgressMs is the progress at that moment
+	resumedAt time.Time
+}
+
+func (player Player) copy() Player {
+	player.Devices = append([]spotify.PlayerDevice{}, player.Devices...)
+	player.Tracks = append([]spotify.ID{}, player.Tracks...)
+	player.Queue = append([]spotify.ID{}, player.Queue...)
+	return player
+}
+
+// Adds a device to the player of a user that was added before and returns its id. The first device becomes active.
+func (server *Server) AddDevice(userId string, name string) spotify.ID {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	player := &server.users[userId].Player
+	device := spotify.PlayerDevice{
+		ID:     server.newId("device"),
+		Name:   name,
+		Type:   "Computer",
+		Volume: player.VolumePercent,
+	}
+	player.Devices = append(player.Devices, device)
+
+	if player.ActiveDeviceId == "" {
+		player.ActiveDeviceId = device.ID
+	}
+
+	return device.ID
+}
+
+// Simulates the current track of a user that was added before playing to the end
+func (server *Server) FinishTrack(userId string) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.advance(&server.users[userId].Player)
+}
+
+func (server *Server) getDevices(w http.ResponseWriter, _ *http.Request, user *User) {
+
+	player := user.Player
+	devices := []spotify.PlayerDevice{}
+	for _, device := range player.Devices {
+		device.Active = device.ID == player.ActiveDeviceId
+		devices = append(devices, device)
+	}
+
+	writeJson(w, http.StatusOK, map[string][]spotify.PlayerDevice{"devices": devices})
+}
+
+func (server *Server) getCurrentlyPlaying(w http.ResponseWriter, _ *http.Request, user *User) {
+
+	player := &user.Player
+	if player.CurrentTrack == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	track := server.tracks[player.CurrentTrack]
+	currentlyPlaying := spotify.CurrentlyPlaying{
+		Timestamp: time.Now().UnixMilli(),
+		Progress:  server.currentProgress(player),
+		Playing:   player.IsPlaying,
+		Item:      &track,
+	}
+	if player.ContextUri != "" {
+		currentlyPlaying.PlaybackContext = spotify.PlaybackContext{
+			Type: "playlist",
+			URI:  player.ContextUri,
+		}
+	}
+
+	writeJson(w, http.StatusOK, currentlyPlaying)
+}
+
+func (server *Server) currentProgress(player *Player) int {
+
+	if !player.IsPlaying {
+		return player.ProgressMs
+	}
+
+	progress := player.ProgressMs + int(time.Since(player.resumedAt).Milliseconds())
+	if duration := server.tracks[player.CurrentTrack].Duration; progress > duration {
+		return duration
+	}
+
+	return progress
+}
+
+// Checks everything the real API checks before it accepts a player command and switches to the requested device
+func controllablePlayer(w http.ResponseWriter, r *http.Request, user *User) (*Player, bool) {
+
+	if user.Product != "premium" {
+		writeError(w, http.StatusForbidden, "Player command failed: Premium required")
+		return nil, false
+	}
+
+	player := &user.Player
+	if rawDeviceId := r.URL.Query().Get("device_id"); rawDeviceId != "" {
+		deviceId := spotify.ID(rawDeviceId)
+		for _, device := range player.Devices {
+			if device.ID == deviceId {
+				player.ActiveDeviceId = deviceId
+				return player, true
+			}
+		}
+
+		writeError(w, http.StatusNotFound, "Device not found")
+		return nil, false
+	}
+
+	if player.ActiveDeviceId == "" {
+		writeError(w, http.StatusNotFound, "Player command failed: No active device found")
+		return nil, false
+	}
+
+	return player, true
+}
+
+func (server *Server) play(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	var options spotify.PlayOptions
+	err := json.NewDecoder(r.Body).Decode(&options)
+	if errors.Is(err, io.EOF) || (err == nil && options.PlaybackContext == nil && len(options.URIs) == 0) {
+		if player.CurrentTrack == "" {
+			writeError(w, http.StatusNotFound, "Player command failed: Nothing to resume")
+			return
+		}
+
+		server.resume(player)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Error parsing JSON.")
+		return
+	}
+
+	var tracks []spotify.ID
+	var contextUri spotify.URI
+	if options.PlaybackContext != nil {
+		contextUri = *options.PlaybackContext
+		playlist, found := server.playlists[idFromUri(string(contextUri))]
+		if !strings.Contains(string(contextUri), ":playlist:") || !found {
+			writeError(w, http.StatusNotFound, "Context not found")
+			return
+		}
+		tracks = playlist.Tracks
+	} else {
+		var valid bool
+		tracks, valid = server.trackIdsFromUris(urisToStrings(options.URIs))
+		if !valid {
+			writeError(w, http.StatusBadRequest, "Invalid track uri")
+			return
+		}
+	}
+
+	position := 0
+	if options.PlaybackOffset != nil {
+		position = options.PlaybackOffset.Position
+		if options.PlaybackOffset.URI != "" {
+			position = indexOf(tracks, idFromUri(string(options.PlaybackOffset.URI)))
+		}
+	}
+	if position < 0 || position >= len(tracks) {
+		writeError(w, http.StatusNotFound, "Player command failed: Restriction violated")
+		return
+	}
+
+	player.ContextUri = contextUri
+	player.Tracks = nil
+	if contextUri == "" {
+		player.Tracks = tracks
+	}
+	server.startTrack(player, position, tracks[position])
+	player.ProgressMs = options.PositionMs
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func urisToStrings(uris []spotify.URI) []string {
+
+	var rawUris []string
+	for _, uri := range uris {
+		rawUris = append(rawUris, string(uri))
+	}
+
+	return rawUris
+}
+
+func indexOf(trackIds []spotify.ID, trackId spotify.ID) int {
+	for i, id := range trackIds {
+		if id == trackId {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (server *Server) startTrack(player *Player, position int, trackId spotify.ID) {
+	player.Position = position
+	player.CurrentTrack = trackId
+	player.ProgressMs = 0
+	player.IsPlaying = true
+	player.resumedAt = time.Now()
+}
+
+func (server *Server) resume(player *Player) {
+	if !player.IsPlaying {
+		player.IsPlaying = true
+		player.resumedAt = time.Now()
+	}
+}
+
+func (server *Server) stop(player *Player) {
+	player.ProgressMs = server.currentProgress(player)
+	player.IsPlaying = false
+}
+
+// Plays the next track of the queue or the context. Without a next track, playback stops.
+func (server *Server) advance(player *Player) {
+
+	if len(player.Queue) > 0 {
+		nextTrack := player.Queue[0]
+		player.Queue = player.Queue[1:]
+		server.startTrack(player, player.Position, nextTrack)
+		return
+	}
+
+	tracks := server.contextTracks(player)
+
+	// SpotiFete changes the queue playlist while it is played, so the current track is looked up again
+	nextPosition := player.Position + 1
+	if currentPosition := indexOf(tracks, player.CurrentTrack); currentPosition >= 0 {
+		nextPosition = currentPosition + 1
+	}
+
+	if nextPosition >= len(tracks) {
+		if player.Repeat != "context" || len(tracks) == 0 {
+			server.stop(player)
+			player.ProgressMs = 0
+			return
+		}
+
+		nextPosition = 0
+	}
+
+	server.startTrack(player, nextPosition, tracks[nextPosition])
+}
+
+func (server *Server) contextTracks(player *Player) []spotify.ID {
+
+	if player.ContextUri == "" {
+		return player.Tracks
+	}
+
+	playlist, found := server.playlists[idFromUri(string(player.ContextUri))]
+	if !found {
+		return nil
+	}
+
+	return playlist.Tracks
+}
+
+func (server *Server) pause(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	if !player.IsPlaying {
+		writeError(w, http.StatusForbidden, "Player command failed: Restriction violated")
+		return
+	}
+
+	server.stop(player)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) next(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	server.advance(player)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) queue(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	trackIds, valid := server.trackIdsFromUris([]string{r.URL.Query().Get("uri")})
+	if !valid {
+		writeError(w, http.StatusBadRequest, "Invalid track uri")
+		return
+	}
+
+	player.Queue = append(player.Queue, trackIds...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) seek(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	if player.CurrentTrack == "" {
+		writeError(w, http.StatusNotFound, "Player command failed: Nothing is playing")
+		return
+	}
+
+	position, err := strconv.Atoi(r.URL.Query().Get("position_ms"))
+	if err != nil || position < 0 {
+		writeError(w, http.StatusBadRequest, "Invalid position_ms")
+		return
+	}
+
+	if position >= server.tracks[player.CurrentTrack].Duration {
+		server.advance(player)
+	} else {
+		player.ProgressMs = position
+		player.resumedAt = time.Now()
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) volume(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	volumePercent, err := strconv.Atoi(r.URL.Query().Get("volume_percent"))
+	if err != nil || volumePercent < 0 || volumePercent > 100 {
+		writeError(w, http.StatusBadRequest, "Invalid volume_percent")
+		return
+	}
+
+	player.VolumePercent = volumePercent
+	for i := range player.Devices {
+		if player.Devices[i].ID == player.ActiveDeviceId {
+			player.Devices[i].Volume = volumePercent
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) shuffle(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	shuffle, err := strconv.ParseBool(r.URL.Query().Get("state"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	player.Shuffle = shuffle
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) repeat(w http.ResponseWriter, r *http.Request, user *User) {
+
+	player, controllable := controllablePlayer(w, r, user)
+	if !controllable {
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state != "track" && state != "context" && state != "off" {
+		writeError(w, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	player.Repeat = state
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,330 @@
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zmb3/spotify"
+)
+
+type Playlist struct {
+	Id          spotify.ID
+	Name        string
+	Description string
+	OwnerId     string
+	Public      bool
+	Tracks      []spotify.ID
+	// The last uploaded image, nil if there is none
+	Image []byte
+	// Ids of the users that follow the playlist. The owner follows it from the start.
+	Followers []string
+	// Increases with every change of the tracks
+	Version int
+}
+
+func (playlist Playlist) copy() Playlist {
+	playlist.Tracks = append([]spotify.ID{}, playlist.Tracks...)
+	playlist.Image = append([]byte(nil), playlist.Image...)
+	playlist.Followers = append([]string{}, playlist.Followers...)
+	return playlist
+}
+
+func (playlist *Playlist) isFollowedBy(userId string) bool {
+	for _, follower := range playlist.Followers {
+		if follower == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Adds a public playlist for a user that was added before. The tracks have to be in the catalog already.
+func (server *Server) AddPlaylist(ownerId string, name string, trackIds ...spotify.ID) spotify.ID {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	playlist := &Playlist{
+		Id:        server.newId("playlist"),
+		Name:      name,
+		OwnerId:   ownerId,
+		Public:    true,
+		Tracks:    append([]spotify.ID{}, trackIds...),
+		Followers: []string{ownerId},
+	}
+	server.playlists[playlist.Id] = playlist
+
+	return playlist.Id
+}
+
+// Returns a copy of the playlist
+func (server *Server) Playlist(id spotify.ID) (Playlist, bool) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	playlist, found := server.playlists[id]
+	if !found {
+		return Playlist{}, false
+	}
+
+	return playlist.copy(), true
+}
+
+func (server *Server) simplePlaylist(playlist *Playlist) spotify.SimplePlaylist {
+
+	var images []spotify.Image
+	if playlist.Image != nil {
+		images = []spotify.Image{{Height: 300, Width: 300, URL: "https://mosaic.scdn.co/300/" + string(playlist.Id)}}
+	}
+
+	return spotify.SimplePlaylist{
+		ID:         playlist.Id,
+		Images:     images,
+		Name:       playlist.Name,
+		Owner:      server.ownerOf(playlist),
+		IsPublic:   playlist.Public,
+		SnapshotID: strconv.Itoa(playlist.Version),
+		Tracks:     spotify.PlaylistTracks{Total: uint(len(playlist.Tracks))},
+		URI:        spotify.URI("spotify:playlist:" + string(playlist.Id)),
+	}
+}
+
+func (server *Server) ownerOf(playlist *Playlist) spotify.User {
+
+	owner, found := server.users[playlist.OwnerId]
+	if !found {
+		return spotify.User{ID: playlist.OwnerId}
+	}
+
+	return publicUser(owner)
+}
+
+func (server *Server) playlistTrackPage(playlist *Playlist, limit int, offset int, market string) spotify.PlaylistTrackPage {
+
+	var playlistTracks []spotify.PlaylistTrack
+	for _, trackId := range pageOf(playlist.Tracks, limit, offset) {
+		playlistTracks = append(playlistTracks, spotify.PlaylistTrack{
+			AddedBy: server.ownerOf(playlist),
+			Track:   trackForMarket(server.tracks[trackId], market),
+		})
+	}
+
+	page := spotify.PlaylistTrackPage{Tracks: playlistTracks}
+	page.Limit, page.Offset, page.Total = limit, offset, len(playlist.Tracks)
+	return page
+}
+
+func (server *Server) findPlaylist(w http.ResponseWriter, r *http.Request) (*Playlist, bool) {
+
+	playlist, found := server.playlists[spotify.ID(r.PathValue("id"))]
+	if !found {
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+
+	return playlist, found
+}
+
+// Only the owner may change a playlist
+func (server *Server) findOwnPlaylist(w http.ResponseWriter, r *http.Request, user *User) (*Playlist, bool) {
+
+	playlist, found := server.findPlaylist(w, r)
+	if !found {
+		return nil, false
+	}
+
+	if playlist.OwnerId != user.Id {
+		writeError(w, http.StatusForbidden, "You cannot modify a playlist you do not own.")
+		return nil, false
+	}
+
+	return playlist, true
+}
+
+func (server *Server) createPlaylist(w http.ResponseWriter, r *http.Request, user *User) {
+
+	if r.PathValue("userId") != user.Id {
+		writeError(w, http.StatusForbidden, "You cannot create a playlist for another user.")
+		return
+	}
+
+	var body struct {
+		Name        string `json:"name"`
+		Public      bool   `json:"public"`
+		Description string `json:"description"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.Name == "" {
+		writeError(w, http.StatusBadRequest, "Missing required field: name")
+		return
+	}
+
+	playlist := &Playlist{
+		Id:          server.newId("playlist"),
+		Name:        body.Name,
+		Description: body.Description,
+		OwnerId:     user.Id,
+		Public:      body.Public,
+		Tracks:      []spotify.ID{},
+		Followers:   []string{user.Id},
+	}
+	server.playlists[playlist.Id] = playlist
+
+	writeJson(w, http.StatusCreated, server.fullPlaylist(playlist))
+}
+
+func (server *Server) fullPlaylist(playlist *Playlist) spotify.FullPlaylist {
+	return spotify.FullPlaylist{
+		SimplePlaylist: server.simplePlaylist(playlist),
+		Description:    playlist.Description,
+		Followers:      spotify.Followers{Count: uint(len(playlist.Followers))},
+		Tracks:         server.playlistTrackPage(playlist, 100, 0, ""),
+	}
+}
+
+func (server *Server) getPlaylist(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	playlist, found := server.findPlaylist(w, r)
+	if !found {
+		return
+	}
+
+	writeJson(w, http.StatusOK, server.fullPlaylist(playlist))
+}
+
+func (server *Server) getPlaylistTracks(w http.ResponseWriter, r *http.Request, _ *User) {
+
+	playlist, found := server.findPlaylist(w, r)
+	if !found {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset := pagingParameters(query.Get("limit"), query.Get("offset"), 100)
+	writeJson(w, http.StatusOK, server.playlistTrackPage(playlist, limit, offset, query.Get("market")))
+}
+
+func (server *Server) addTracksToPlaylist(w http.ResponseWriter, r *http.Request, user *User) {
+
+	playlist, found := server.findOwnPlaylist(w, r, user)
+	if !found {
+		return
+	}
+
+	var body struct {
+		Uris []string `json:"uris"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Error parsing JSON.")
+		return
+	}
+
+	trackIds, valid := server.trackIdsFromUris(body.Uris)
+	if !valid {
+		writeError(w, http.StatusBadRequest, "Invalid track uri")
+		return
+	}
+
+	playlist.Tracks = append(playlist.Tracks, trackIds...)
+	playlist.Version++
+
+	writeJson(w, http.StatusCreated, map[string]string{"snapshot_id": strconv.Itoa(playlist.Version)})
+}
+
+func (server *Server) replacePlaylistTracks(w http.ResponseWriter, r *http.Request, user *User) {
+
+	playlist, found := server.findOwnPlaylist(w, r, user)
+	if !found {
+		return
+	}
+
+	var uris []string
+	if rawUris := r.URL.Query().Get("uris"); rawUris != "" {
+		uris = strings.Split(rawUris, ",")
+	}
+
+	trackIds, valid := server.trackIdsFromUris(uris)
+	if !valid {
+		writeError(w, http.StatusBadRequest, "Invalid track uri")
+		return
+	}
+
+	playlist.Tracks = trackIds
+	playlist.Version++
+
+	writeJson(w, http.StatusCreated, map[string]string{"snapshot_id": strconv.Itoa(playlist.Version)})
+}
+
+func (server *Server) trackIdsFromUris(uris []string) ([]spotify.ID, bool) {
+
+	trackIds := []spotify.ID{}
+	for _, uri := range uris {
+		if !strings.HasPrefix(uri, "spotify:track:") {
+			return nil, false
+		}
+
+		trackId := idFromUri(uri)
+		if _, found := server.tracks[trackId]; !found {
+			return nil, false
+		}
+
+		trackIds = append(trackIds, trackId)
+	}
+
+	return trackIds, true
+}
+
+func (server *Server) setPlaylistImage(w http.ResponseWriter, r *http.Request, user *User) {
+
+	playlist, found := server.findOwnPlaylist(w, r, user)
+	if !found {
+		return
+	}
+
+	image, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, r.Body))
+	if err != nil || len(image) == 0 {
+		writeError(w, http.StatusBadRequest, "Invalid image.")
+		return
+	}
+
+	playlist.Image = image
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (server *Server) followPlaylist(w http.ResponseWriter, r *http.Request, user *User) {
+
+	playlist, found := server.findPlaylist(w, r)
+	if !found {
+		return
+	}
+
+	if !playlist.isFollowedBy(user.Id) {
+		playlist.Followers = append(playlist.Followers, user.Id)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (server *Server) unfollowPlaylist(w http.ResponseWriter, r *http.Request, user *User) {
+
+	playlist, found := server.findPlaylist(w, r)
+	if !found {
+		return
+	}
+
+	var remainingFollowers []string
+	for _, follower := range playlist.Followers {
+		if follower != user.Id {
+			remainingFollowers = append(remainingFollowers, follower)
+		}
+	}
+	playlist.Followers = remainingFollowers
+
+	w.WriteHeader(http.StatusOK)
+}
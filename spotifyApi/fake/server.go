@@ -0,0 +1,272 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
+)
+
+// An in-memory fake of the parts of the Spotify Web API SpotiFete uses, served by an httptest.Server.
+// It knows only the users, tracks and playlists added to it and simulates one player per user.
+// Point SpotiFete at it with authentication.SetClientFactory(server.NewClient).
+type Server struct {
+	mutex      sync.Mutex
+	httpServer *httptest.Server
+	lastId     int
+
+	users        map[string]*User
+	usersByToken map[string]*User
+	tracks       map[spotify.ID]spotify.FullTrack
+	albums       map[spotify.ID]spotify.FullAlbum
+	artists      map[spotify.ID]spotify.FullArtist
+	playlists    map[spotify.ID]*Playlist
+
+	// The next requests are answered with this status instead of being handled
+	failingRequests   int
+	failureStatus     int
+	failureRetryAfter int
+}
+
+type User struct {
+	Id          string
+	DisplayName string
+	Country     string
+	// "premium" or "free"
+	Product     string
+	AccessToken string
+	Player      Player
+}
+
+// Starts a new fake Spotify API. It has to be closed once it is not needed anymore.
+func NewServer() *Server {
+
+	server := &Server{
+		users:        map[string]*User{},
+		usersByToken: map[string]*User{},
+		tracks:       map[spotify.ID]spotify.FullTrack{},
+		albums:       map[spotify.ID]spotify.FullAlbum{},
+		artists:      map[spotify.ID]spotify.FullArtist{},
+		playlists:    map[spotify.ID]*Playlist{},
+	}
+	server.httpServer = httptest.NewServer(server.routes())
+
+	return server
+}
+
+func (server *Server) Close() {
+	server.httpServer.Close()
+}
+
+func (server *Server) URL() string {
+	return server.httpServer.URL
+}
+
+func (server *Server) routes() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/me", server.authenticated(server.getCurrentUser))
+	mux.HandleFunc("GET /v1/search", server.authenticated(server.search))
+	mux.HandleFunc("GET /v1/tracks/{id}", server.authenticated(server.getTrack))
+	mux.HandleFunc("GET /v1/albums/{id}", server.authenticated(server.getAlbum))
+	mux.HandleFunc("GET /v1/artists/{id}", server.authenticated(server.getArtist))
+
+	mux.HandleFunc("POST /v1/users/{userId}/playlists", server.authenticated(server.createPlaylist))
+	mux.HandleFunc("PUT /v1/users/{userId}/playlists/{id}/followers", server.authenticated(server.followPlaylist))
+	mux.HandleFunc("DELETE /v1/users/{userId}/playlists/{id}/followers", server.authenticated(server.unfollowPlaylist))
+	mux.HandleFunc("GET /v1/playlists/{id}", server.authenticated(server.getPlaylist))
+	mux.HandleFunc("GET /v1/playlists/{id}/tracks", server.authenticated(server.getPlaylistTracks))
+	mux.HandleFunc("POST /v1/playlists/{id}/tracks", server.authenticated(server.addTracksToPlaylist))
+	mux.HandleFunc("PUT /v1/playlists/{id}/tracks", server.authenticated(server.replacePlaylistTracks))
+	mux.HandleFunc("PUT /v1/playlists/{id}/images", server.authenticated(server.setPlaylistImage))
+
+	mux.HandleFunc("GET /v1/me/player/devices", server.authenticated(server.getDevices))
+	mux.HandleFunc("GET /v1/me/player/currently-playing", server.authenticated(server.getCurrentlyPlaying))
+	mux.HandleFunc("PUT /v1/me/player/play", server.authenticated(server.play))
+	mux.HandleFunc("PUT /v1/me/player/pause", server.authenticated(server.pause))
+	mux.HandleFunc("POST /v1/me/player/next", server.authenticated(server.next))
+	mux.HandleFunc("POST /v1/me/player/queue", server.authenticated(server.queue))
+	mux.HandleFunc("PUT /v1/me/player/seek", server.authenticated(server.seek))
+	mux.HandleFunc("PUT /v1/me/player/volume", server.authenticated(server.volume))
+	mux.HandleFunc("PUT /v1/me/player/shuffle", server.authenticated(server.shuffle))
+	mux.HandleFunc("PUT /v1/me/player/repeat", server.authenticated(server.repeat))
+
+	return mux
+}
+
+// Looks up the user for the access token and holds the lock of the server while the handler runs
+func (server *Server) authenticated(handler func(w http.ResponseWriter, r *http.Request, user *User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		if server.failingRequests > 0 {
+			server.failingRequests--
+			if server.failureStatus == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", strconv.Itoa(server.failureRetryAfter))
+			}
+			writeError(w, server.failureStatus, http.StatusText(server.failureStatus))
+			return
+		}
+
+		accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		user, found := server.usersByToken[accessToken]
+		if !found {
+			writeError(w, http.StatusUnauthorized, "Invalid access token")
+			return
+		}
+
+		handler(w, r, user)
+	}
+}
+
+// Answers the next requests with 429 Too Many Requests and the given Retry-After header
+func (server *Server) Throttle(requests int, retryAfterSeconds int) {
+	server.failRequests(requests, http.StatusTooManyRequests, retryAfterSeconds)
+}
+
+// Answers the next requests with 503 Service Unavailable
+func (server *Server) FailRequests(requests int) {
+	server.failRequests(requests, http.StatusServiceUnavailable, 0)
+}
+
+func (server *Server) failRequests(requests int, status int, retryAfterSeconds int) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.failingRequests = requests
+	server.failureStatus = status
+	server.failureRetryAfter = retryAfterSeconds
+}
+
+// Creates a client that sends all requests of the user with the given token to this server.
+// Like the real clients, it retries throttled and failed requests.
+func (server *Server) NewClient(token *oauth2.Token) spotifyApi.Client {
+
+	httpClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(token),
+			Base:   spotifyApi.NewTransport(redirectingTransport{target: server.httpServer.URL}),
+		},
+	}
+
+	client := spotify.NewClient(httpClient)
+	return &client
+}
+
+// Sends requests for api.spotify.com to the fake server instead
+type redirectingTransport struct {
+	target string
+}
+
+func (transport redirectingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	targetUrl, err := url.Parse(transport.target)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectedRequest := request.Clone(request.Context())
+	redirectedRequest.URL.Scheme = targetUrl.Scheme
+	redirectedRequest.URL.Host = targetUrl.Host
+	redirectedRequest.Host = targetUrl.Host
+
+	return http.DefaultTransport.RoundTrip(redirectedRequest)
+}
+
+// Adds a user and returns a token that never expires for it
+func (server *Server) AddUser(id string, displayName string, country string, product string) *oauth2.Token {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	user := &User{
+		Id:          id,
+		DisplayName: displayName,
+		Country:     country,
+		Product:     product,
+		AccessToken: "fake-access-token-" + id,
+		Player: Player{
+			VolumePercent: 100,
+			Repeat:        "off",
+		},
+	}
+	server.users[id] = user
+	server.usersByToken[user.AccessToken] = user
+
+	return &oauth2.Token{
+		AccessToken:  user.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: "fake-refresh-token-" + id,
+		Expiry:       time.Now().AddDate(100, 0, 0),
+	}
+}
+
+// Returns a copy of the user, including the current state of its player
+func (server *Server) User(id string) (User, bool) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	user, found := server.users[id]
+	if !found {
+		return User{}, false
+	}
+
+	userCopy := *user
+	userCopy.Player = user.Player.copy()
+	return userCopy, true
+}
+
+func (server *Server) getCurrentUser(w http.ResponseWriter, _ *http.Request, user *User) {
+	writeJson(w, http.StatusOK, spotify.PrivateUser{
+		User:    publicUser(user),
+		Country: user.Country,
+		Product: user.Product,
+	})
+}
+
+func publicUser(user *User) spotify.User {
+	return spotify.User{
+		DisplayName: user.DisplayName,
+		ID:          user.Id,
+		URI:         spotify.URI("spotify:user:" + user.Id),
+	}
+}
+
+func (server *Server) newId(prefix string) spotify.ID {
+	server.lastId++
+	return spotify.ID(fmt.Sprintf("%s%d", prefix, server.lastId))
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Errors look like the ones of the real API, so the client decodes them into a spotify.Error
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, map[string]spotify.Error{
+		"error": {
+			Status:  status,
+			Message: message,
+		},
+	})
+}
+
+func idFromUri(uri string) spotify.ID {
+	parts := strings.Split(uri, ":")
+	return spotify.ID(parts[len(parts)-1])
+}
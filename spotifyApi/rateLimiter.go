@@ -0,0 +1,91 @@
+package spotifyApi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+const (
+	requestsPerSecond = 5
+	burstSize         = 10
+	// Requests fail right away instead of waiting longer than this for Spotify to accept requests again
+	maxRateLimitWait = 10 * time.Second
+)
+
+// A token bucket for the requests of one Spotify user. Spotify rate limits the whole app, so a single busy session
+// should not use up the requests of all others.
+type rateLimiter struct {
+	mutex       sync.Mutex
+	tokens      float64
+	lastRefill  time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		tokens:     burstSize,
+		lastRefill: time.Now(),
+	}
+}
+
+// Blocks until the next request may be sent
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+
+	for {
+		delay := limiter.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if delay > maxRateLimitWait {
+			return ErrSpotifyThrottled
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Takes a token if there is one, otherwise returns how long to wait before trying again
+func (limiter *rateLimiter) reserve() time.Duration {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	if now.Before(limiter.pausedUntil) {
+		return limiter.pausedUntil.Sub(now)
+	}
+
+	limiter.tokens += now.Sub(limiter.lastRefill).Seconds() * requestsPerSecond
+	if limiter.tokens > burstSize {
+		limiter.tokens = burstSize
+	}
+	limiter.lastRefill = now
+
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - limiter.tokens) / requestsPerSecond * float64(time.Second))
+}
+
+// No request is sent until the given time has passed, e.g. because Spotify asked us to back off
+func (limiter *rateLimiter) pause(duration time.Duration) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	pausedUntil := time.Now().Add(duration)
+	if pausedUntil.After(limiter.pausedUntil) {
+		limiter.pausedUntil = pausedUntil
+	}
+}
@@ -0,0 +1,67 @@
+package spotifyApi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+
+	limiter := newRateLimiter()
+
+	for i := 0; i < burstSize; i++ {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("request %d has to wait %s, want no wait within the burst", i+1, delay)
+		}
+	}
+
+	delay := limiter.reserve()
+	if delay <= 0 || delay > time.Second/requestsPerSecond {
+		t.Errorf("request after the burst has to wait %s, want up to %s", delay, time.Second/requestsPerSecond)
+	}
+}
+
+func TestRateLimiterRefillsTokens(t *testing.T) {
+
+	limiter := newRateLimiter()
+	limiter.tokens = 0
+	limiter.lastRefill = time.Now().Add(-time.Second)
+
+	for i := 0; i < requestsPerSecond; i++ {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("request %d has to wait %s, want %d tokens after one second", i+1, delay, requestsPerSecond)
+		}
+	}
+
+	if delay := limiter.reserve(); delay == 0 {
+		t.Errorf("request did not have to wait, want all tokens used up")
+	}
+}
+
+func TestRateLimiterDoesNotRefillBeyondBurst(t *testing.T) {
+
+	limiter := newRateLimiter()
+	limiter.lastRefill = time.Now().Add(-time.Hour)
+
+	limiter.reserve()
+	if limiter.tokens > burstSize-1 {
+		t.Errorf("limiter has %f tokens left, want at most %d", limiter.tokens, burstSize-1)
+	}
+}
+
+func TestPausedRateLimiterHoldsBackRequests(t *testing.T) {
+
+	limiter := newRateLimiter()
+	limiter.pause(5 * time.Second)
+
+	delay := limiter.reserve()
+	if delay < 4*time.Second || delay > 5*time.Second {
+		t.Errorf("request has to wait %s, want about 5s", delay)
+	}
+
+	// A shorter pause does not end a longer one early
+	limiter.pause(time.Second)
+	if delay := limiter.reserve(); delay < 4*time.Second {
+		t.Errorf("request has to wait %s after a shorter pause, want about 5s", delay)
+	}
+}
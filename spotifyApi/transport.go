@@ -0,0 +1,142 @@
+package spotifyApi
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/logger"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+const (
+	maxRetries = 3
+	// Used if Spotify does not say how long to wait
+	defaultRetryAfter     = time.Second
+	minServerErrorBackoff = 250 * time.Millisecond
+)
+
+// Sends the requests of one user to Spotify. It holds back requests according to a rate limit, waits and retries
+// if Spotify answers with 429 Too Many Requests and retries idempotent requests that failed with a server error.
+// If Spotify keeps throttling, the request fails with an error wrapping ErrSpotifyThrottled.
+type retryingTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+// Every transport has its own rate limit, so there should be one per user and not one per request
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return &retryingTransport{
+		base:    base,
+		limiter: newRateLimiter(),
+	}
+}
+
+func (transport *retryingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	for attempt := 0; ; attempt++ {
+		err := transport.limiter.wait(request.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		attemptRequest, err := requestForAttempt(request, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := transport.base.RoundTrip(attemptRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		canRetry := attempt < maxRetries && isReplayable(request)
+
+		if response.StatusCode == http.StatusTooManyRequests {
+			retryAfter := parseRetryAfter(response)
+			discard(response)
+
+			// Other requests of the user would only be throttled too
+			transport.limiter.pause(retryAfter)
+
+			if !canRetry || retryAfter > maxRateLimitWait {
+				return nil, fmt.Errorf("%w: %s %s, retry after %s", ErrSpotifyThrottled, request.Method, request.URL.Path, retryAfter)
+			}
+
+			logger.Warningf("Spotify is throttling requests, retrying %s %s in %s.", request.Method, request.URL.Path, retryAfter)
+			continue
+		}
+
+		if response.StatusCode >= 500 && canRetry && isIdempotent(request) {
+			discard(response)
+
+			backoff := serverErrorBackoff(attempt)
+			logger.Warningf("Spotify responded with status %d to %s %s, retrying in %s.", response.StatusCode, request.Method, request.URL.Path, backoff)
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-request.Context().Done():
+				timer.Stop()
+				return nil, request.Context().Err()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		return response, nil
+	}
+}
+
+// The body of the original request has been read by the first attempt, so every retry needs a fresh copy
+func requestForAttempt(request *http.Request, attempt int) (*http.Request, error) {
+
+	if attempt == 0 || request.Body == nil || request.Body == http.NoBody {
+		return request, nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retryRequest := request.Clone(request.Context())
+	retryRequest.Body = body
+	return retryRequest, nil
+}
+
+// Streamed bodies like the playlist image can not be sent again
+func isReplayable(request *http.Request) bool {
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// A POST might have been processed although Spotify answered with an error, e.g. adding a track to the queue twice
+func isIdempotent(request *http.Request) bool {
+	return request.Method == http.MethodGet || request.Method == http.MethodPut || request.Method == http.MethodDelete
+}
+
+func parseRetryAfter(response *http.Response) time.Duration {
+
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return defaultRetryAfter
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// Doubles with every attempt and adds up to 50% jitter, so retries of several sessions do not hit Spotify at once
+func serverErrorBackoff(attempt int) time.Duration {
+
+	backoff := minServerErrorBackoff << attempt
+	jitter := time.Duration(rand.Int63n(int64(backoff / 2)))
+	return backoff + jitter
+}
+
+// Reading the body to the end allows the connection to be reused
+func discard(response *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+	_ = response.Body.Close()
+}
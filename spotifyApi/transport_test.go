@@ -0,0 +1,144 @@
+package spotifyApi_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/partyoffice/spotifete/spotifyApi/fake"
+	"github.com/zmb3/spotify"
+)
+
+func newFakeClient(t *testing.T) (*fake.Server, spotifyApi.Client) {
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+
+	token := server.AddUser("owner", "Owner", "DE", "premium")
+	server.AddTrack(fake.NewTrack("track1", "Track 1", "Artist", "Album", 180000))
+	server.AddTrack(fake.NewTrack("track2", "Track 2", "Artist", "Album", 200000))
+
+	return server, server.NewClient(token)
+}
+
+func TestThrottledRequestIsRetriedAfterRetryAfter(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	server.Throttle(1, 1)
+
+	start := time.Now()
+	track, err := client.GetTrack("track1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if track.ID != "track1" {
+		t.Errorf("got track %s, want track1", track.ID)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("request was retried after %s, want at least the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestThrottlingPausesAllRequestsOfTheClient(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	server.Throttle(1, 1)
+
+	_, err := client.GetTrack("track1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	// The pause is over once the retry went through, so the next request is sent right away
+	start := time.Now()
+	_, err = client.GetTrack("track2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request after the pause took %s", elapsed)
+	}
+}
+
+func TestRequestFailsIfSpotifyKeepsThrottling(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	server.Throttle(10, 0)
+
+	_, err := client.GetTrack("track1")
+	if !errors.Is(err, ErrSpotifyThrottled) {
+		t.Fatalf("got error %v, want ErrSpotifyThrottled", err)
+	}
+}
+
+func TestRequestFailsRightAwayIfRetryAfterIsTooLong(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	server.Throttle(1, 60)
+
+	start := time.Now()
+	_, err := client.GetTrack("track1")
+	if !errors.Is(err, ErrSpotifyThrottled) {
+		t.Fatalf("got error %v, want ErrSpotifyThrottled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request failed after %s, want right away", elapsed)
+	}
+
+	// Later requests of the client fail too instead of waiting a minute
+	_, err = client.GetTrack("track2")
+	if !errors.Is(err, ErrSpotifyThrottled) {
+		t.Fatalf("got error %v, want ErrSpotifyThrottled", err)
+	}
+}
+
+func TestIdempotentRequestIsRetriedAfterServerError(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	server.FailRequests(2)
+
+	track, err := client.GetTrack("track1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if track.ID != "track1" {
+		t.Errorf("got track %s, want track1", track.ID)
+	}
+}
+
+func TestRetriedRequestSendsTheBodyAgain(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	playlistId := server.AddPlaylist("owner", "Playlist")
+	server.FailRequests(1)
+
+	err := client.ReplacePlaylistTracks(playlistId, "track1", "track2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	playlist, _ := server.Playlist(playlistId)
+	if len(playlist.Tracks) != 2 {
+		t.Errorf("playlist has %d tracks, want 2", len(playlist.Tracks))
+	}
+}
+
+func TestNonIdempotentRequestIsNotRetriedAfterServerError(t *testing.T) {
+
+	server, client := newFakeClient(t)
+	playlistId := server.AddPlaylist("owner", "Playlist")
+	server.FailRequests(1)
+
+	_, err := client.AddTracksToPlaylist(playlistId, "track1")
+	var spotifyError spotify.Error
+	if !errors.As(err, &spotifyError) || spotifyError.Status != 503 {
+		t.Fatalf("got error %v, want the 503 of Spotify", err)
+	}
+
+	playlist, _ := server.Playlist(playlistId)
+	if len(playlist.Tracks) != 0 {
+		t.Errorf("playlist has %d tracks, want none", len(playlist.Tracks))
+	}
+}
@@ -19,6 +19,7 @@ func CreateAuthenticatedUser(token *oauth2.Token, loginSession model.LoginSessio
 	}
 
 	persistedUser := getOrCreateFromSpotifyUser(spotifyUser)
+	persistedUser = persistedUser.SetProfile(*spotifyUser)
 	persistedUser = persistedUser.SetToken(token)
 	database.GetConnection().Save(persistedUser)
 
@@ -3,20 +3,27 @@ package users
 import (
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/partyoffice/spotifete/authentication"
 	"github.com/partyoffice/spotifete/database"
 	"github.com/partyoffice/spotifete/database/model"
 	. "github.com/partyoffice/spotifete/shared"
-	"github.com/zmb3/spotify"
+	"github.com/partyoffice/spotifete/spotifyApi"
 )
 
-var clientCache = map[uint]*spotify.Client{}
+// The poller and the API handlers share the client of a user, and with it the user's rate limit for Spotify requests
+var clientCache = map[uint]spotifyApi.Client{}
+var clientCacheMutex sync.Mutex
+
+func Client(user model.SimpleUser) spotifyApi.Client {
+	clientCacheMutex.Lock()
+	defer clientCacheMutex.Unlock()
 
-func Client(user model.SimpleUser) *spotify.Client {
 	if client, ok := clientCache[user.ID]; ok {
 		go refreshAndSaveTokenForUserIfNecessary(client, user.ID)
+		go refreshProfileIfNecessary(client, user)
 		return client
 	}
 
@@ -26,13 +33,14 @@ func Client(user model.SimpleUser) *spotify.Client {
 	}
 
 	client := authentication.NewClientForToken(token)
-	clientCache[user.ID] = &client
-	go refreshAndSaveTokenForUserIfNecessary(&client, user.ID)
+	clientCache[user.ID] = client
+	go refreshAndSaveTokenForUserIfNecessary(client, user.ID)
+	go refreshProfileIfNecessary(client, user)
 
-	return &client
+	return client
 }
 
-func refreshAndSaveTokenForUserIfNecessary(client *spotify.Client, userId uint) *SpotifeteError {
+func refreshAndSaveTokenForUserIfNecessary(client spotifyApi.Client, userId uint) *SpotifeteError {
 	user := FindSimpleUser(model.SimpleUser{
 		BaseModel: model.BaseModel{ID: userId},
 	})
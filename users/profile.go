@@ -0,0 +1,52 @@
+package users
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+	"github.com/partyoffice/spotifete/spotifyApi"
+	"github.com/patrickmn/go-cache"
+)
+
+// Display name, country and subscription level rarely change, so they are only fetched from Spotify every few hours
+const profileRefreshInterval = 6 * time.Hour
+
+// Remembers whose profile was refreshed recently, so the requests of a busy session do not all ask Spotify again
+var recentProfileRefreshes = cache.New(profileRefreshInterval, time.Hour)
+
+func refreshProfileIfNecessary(client spotifyApi.Client, user model.SimpleUser) {
+
+	if user.ProfileRefreshedAt != nil && time.Since(*user.ProfileRefreshedAt) < profileRefreshInterval {
+		return
+	}
+
+	cacheKey := strconv.FormatUint(uint64(user.ID), 10)
+	err := recentProfileRefreshes.Add(cacheKey, true, cache.DefaultExpiration)
+	if err != nil {
+		// Already refreshed or currently being refreshed
+		return
+	}
+
+	spotifyUser, err := client.CurrentUser()
+	if err != nil {
+		// Try again soon instead of waiting for the whole interval
+		recentProfileRefreshes.Set(cacheKey, true, time.Minute)
+		NewError("Could not refresh user profile from Spotify.", err, http.StatusInternalServerError)
+		return
+	}
+
+	updatedUser := user.SetProfile(*spotifyUser)
+	err = database.GetConnection().Model(&model.SimpleUser{BaseModel: model.BaseModel{ID: user.ID}}).Updates(map[string]interface{}{
+		"spotify_display_name": updatedUser.SpotifyDisplayName,
+		"country":              updatedUser.Country,
+		"product":              updatedUser.Product,
+		"profile_refreshed_at": updatedUser.ProfileRefreshedAt,
+	}).Error
+	if err != nil {
+		NewInternalError("could not save refreshed user profile", err)
+	}
+}
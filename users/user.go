@@ -3,6 +3,7 @@ package users
 import (
 	"fmt"
 
+	"github.com/partyoffice/spotifete/config"
 	"github.com/partyoffice/spotifete/database"
 	"github.com/partyoffice/spotifete/database/model"
 	. "github.com/partyoffice/spotifete/shared"
@@ -47,3 +48,13 @@ func FindFullUsers(filter model.SimpleUser) []model.FullUser {
 	database.GetConnection().Where(filter).Preload("ListeningSessions", "active = true").Find(&users)
 	return users
 }
+
+func IsAdmin(user model.SimpleUser) bool {
+	for _, adminSpotifyId := range config.Get().SpotifeteConfiguration.AdminSpotifyIds {
+		if adminSpotifyId == user.SpotifyId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package listeningSession
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+)
+
+const (
+	// Proxies tend to close connections that are silent for too long
+	sessionEventsKeepAliveInterval = 30 * time.Second
+	// Sent once whenever a client connects
+	sessionEventQueueState = "QUEUE_STATE"
+)
+
+func sessionEvents(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	events, unsubscribe := listeningSession.SubscribeToSessionEvents(*session)
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sessionEventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	// Events published before the client subscribed are lost, e.g. while the page loaded or the connection was re-established.
+	// The client compares this timestamp with the one it was rendered with to find out whether it missed something.
+	c.SSEvent(sessionEventQueueState, gin.H{
+		"queue_last_updated_ms": listeningSession.GetQueueLastUpdated(*session).UnixMilli(),
+	})
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+			return event.Type != listeningSession.EventSessionClosed
+		case <-keepAlive.C:
+			c.SSEvent("keep-alive", gin.H{"time": time.Now()})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
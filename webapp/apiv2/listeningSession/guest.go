@@ -0,0 +1,81 @@
+package listeningSession
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	"github.com/partyoffice/spotifete/shared"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+)
+
+func joinSession(c *gin.Context) {
+	request := JoinSessionRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid requestBody: " + err.Error()})
+		return
+	}
+
+	spotifeteError := request.Validate()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	guest, guestToken, spotifeteError := listeningSession.JoinSession(*session, request.DisplayName, request.GuestToken, c.ClientIP())
+	if spotifeteError == nil {
+		c.JSON(http.StatusOK, JoinSessionResponse{
+			GuestToken: guestToken,
+			Guest:      guest,
+		})
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
+}
+
+func getGuestRequests(c *gin.Context) {
+	guestToken := c.Query("guestToken")
+	if guestToken == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Missing query parameter 'guestToken'."})
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	guest, spotifeteError := listeningSession.FindGuestForToken(*session, guestToken)
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	requests, err := listeningSession.GetQueuedRequestsOfGuest(*session, *guest)
+	if err != nil {
+		SetJsonError(*shared.NewInternalError("could not get requests of guest", err), c)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetGuestRequestsResponse{
+		Guest:    *guest,
+		Requests: requests,
+	})
+}
@@ -0,0 +1,58 @@
+package listeningSession
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+)
+
+func getPlayHistory(c *gin.Context) {
+	page, err := parseIntQuery(c, "page", 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid page."})
+		return
+	}
+
+	pageSize, err := parseIntQuery(c, "page_size", listeningSession.DefaultHistoryPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid page size."})
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	history, totalCount, spotifeteError := listeningSession.GetPlayHistory(*session, page, pageSize)
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetPlayHistoryResponse{
+		History:    history,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+	})
+}
+
+func parseIntQuery(c *gin.Context, key string, defaultValue int) (int, error) {
+
+	parameter := c.Query(key)
+	if len(parameter) == 0 {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(parameter)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/partyoffice/spotifete/database/model"
 	"github.com/partyoffice/spotifete/listeningSession"
+	"github.com/partyoffice/spotifete/users"
 	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
 )
 
@@ -79,7 +80,7 @@ func closeSession(c *gin.Context) {
 
 func getSessionQueue(c *gin.Context) {
 	joinId := c.Param("joinId")
-	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
 		JoinId: joinId,
 		Active: true,
 	})
@@ -88,13 +89,50 @@ func getSessionQueue(c *gin.Context) {
 		return
 	}
 
-	queue, err := listeningSession.GetFullQueue(*session)
+	queue, err := listeningSession.GetFullQueue(session.SimpleListeningSession)
 	if err != nil {
 		SetJsonError(*shared.NewInternalError("could not get full queue", err), c)
+		return
+	}
+
+	// Without the progress of the current track the estimates are a little less accurate, but still useful
+	var nowPlaying *listeningSession.NowPlaying
+	currentlyPlaying, spotifeteError := listeningSession.GetNowPlaying(*session)
+	if spotifeteError == nil {
+		nowPlaying = &currentlyPlaying
 	}
+
+	estimatedStartTimes := listeningSession.EstimateStartTimes(queue, nowPlaying)
+	queueEntries := make([]QueueEntryResponse, len(queue))
+	for i, request := range queue {
+		queueEntries[i] = QueueEntryResponse{
+			SongRequest:        request,
+			EstimatedStartTime: estimatedStartTimes[i],
+		}
+	}
+
 	c.JSON(http.StatusOK, GetSessionQueueResponse{
-		Queue: queue,
+		Queue: queueEntries,
+	})
+}
+
+func getNowPlaying(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
 	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	nowPlaying, spotifeteError := listeningSession.GetNowPlaying(*session)
+	if spotifeteError == nil {
+		c.JSON(http.StatusOK, nowPlaying)
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
 }
 
 func deleteRequestFromQueue(c *gin.Context) {
@@ -169,6 +207,28 @@ func qrCode(c *gin.Context) {
 	c.Data(http.StatusOK, "image/png", qrCode.Bytes())
 }
 
+// Hit and miss counts of the search and track lookup caches shared by all sessions
+func getSearchCacheStats(c *gin.Context) {
+	loginSessionId := c.Query("loginSessionId")
+	if loginSessionId == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Missing query parameter 'loginSessionId'."})
+		return
+	}
+
+	authenticatedUser, spotifeteError := AuthenticatedRequest{LoginSessionId: loginSessionId}.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	if !users.IsAdmin(authenticatedUser) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Message: "Only admins can see the cache statistics."})
+		return
+	}
+
+	c.JSON(http.StatusOK, listeningSession.SpotifyCacheStats())
+}
+
 func searchTrack(c *gin.Context) {
 	joinId := c.Param("joinId")
 	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
@@ -273,7 +333,13 @@ func requestTrack(c *gin.Context) {
 		return
 	}
 
-	_, spotifeteError = listeningSession.RequestSong(*session, request.TrackId, request.Username)
+	guest, spotifeteError := listeningSession.FindGuestForToken(session.SimpleListeningSession, request.GuestToken)
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	_, spotifeteError = listeningSession.RequestSong(*session, request.TrackId, *guest)
 	if spotifeteError == nil {
 		c.Status(http.StatusNoContent)
 	} else {
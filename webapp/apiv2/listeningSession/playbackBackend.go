@@ -0,0 +1,60 @@
+package listeningSession
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+)
+
+func getPlaybackBackends(c *gin.Context) {
+	var playbackBackends []PlaybackBackendResponse
+	for _, backend := range listeningSession.PlaybackBackends() {
+		playbackBackends = append(playbackBackends, PlaybackBackendResponse{
+			Name:        backend.Name(),
+			Description: backend.Description(),
+		})
+	}
+
+	c.JSON(http.StatusOK, GetPlaybackBackendsResponse{PlaybackBackends: playbackBackends})
+}
+
+func setPlaybackBackend(c *gin.Context) {
+	request := SetPlaybackBackendRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid requestBody: " + err.Error()})
+		return
+	}
+
+	spotifeteError := request.Validate()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	authenticatedUser, spotifeteError := request.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	spotifeteError = listeningSession.SetPlaybackBackend(*session, authenticatedUser, request.PlaybackBackend)
+	if spotifeteError == nil {
+		c.Status(http.StatusNoContent)
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
+}
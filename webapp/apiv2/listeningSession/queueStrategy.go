@@ -0,0 +1,60 @@
+package listeningSession
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+)
+
+func getQueueStrategies(c *gin.Context) {
+	var queueStrategies []QueueStrategyResponse
+	for _, strategy := range listeningSession.QueueStrategies() {
+		queueStrategies = append(queueStrategies, QueueStrategyResponse{
+			Name:        strategy.Name(),
+			Description: strategy.Description(),
+		})
+	}
+
+	c.JSON(http.StatusOK, GetQueueStrategiesResponse{QueueStrategies: queueStrategies})
+}
+
+func setQueueStrategy(c *gin.Context) {
+	request := SetQueueStrategyRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid requestBody: " + err.Error()})
+		return
+	}
+
+	spotifeteError := request.Validate()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	authenticatedUser, spotifeteError := request.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	spotifeteError = listeningSession.SetQueueStrategy(*session, authenticatedUser, request.QueueStrategy)
+	if spotifeteError == nil {
+		c.Status(http.StatusNoContent)
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
+}
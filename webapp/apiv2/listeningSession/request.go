@@ -18,14 +18,41 @@ func (r NewSessionRequest) Validate() *SpotifeteError {
 	return nil
 }
 
+type JoinSessionRequest struct {
+	DisplayName string `json:"display_name"`
+	// Optional, a guest that already joined keeps its identity
+	GuestToken string `json:"guest_token"`
+}
+
+func (r JoinSessionRequest) Validate() *SpotifeteError {
+	if "" == r.DisplayName {
+		return NewUserError("Missing parameter display_name.")
+	}
+
+	return nil
+}
+
+type GuestRequest struct {
+	GuestToken string `json:"guest_token"`
+}
+
+func (r GuestRequest) Validate() *SpotifeteError {
+	if "" == r.GuestToken {
+		return NewUserError("Missing parameter guest_token.")
+	}
+
+	return nil
+}
+
 type RequestTrackRequest struct {
-	Username string `json:"username"`
-	TrackId  string `json:"track_id"`
+	GuestRequest
+	TrackId string `json:"track_id"`
 }
 
 func (r RequestTrackRequest) Validate() *SpotifeteError {
-	if "" == r.Username {
-		return NewUserError("Missing parameter username.")
+	spotifeteError := r.GuestRequest.Validate()
+	if spotifeteError != nil {
+		return spotifeteError
 	}
 
 	if "" == r.TrackId {
@@ -55,7 +82,7 @@ type SetFallbackPlaylistShuffleRequest struct {
 
 type DeleteRequestFromQueueRequest struct {
 	AuthenticatedRequest
-	SpotifyTrackId string `json:"spotify_track_id""`
+	SpotifyTrackId string `json:"spotify_track_id"`
 }
 
 func (r DeleteRequestFromQueueRequest) Validate() *SpotifeteError {
@@ -65,3 +92,263 @@ func (r DeleteRequestFromQueueRequest) Validate() *SpotifeteError {
 
 	return nil
 }
+
+type VoteRequest struct {
+	GuestRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+	Vote           string `json:"vote"`
+}
+
+func (r VoteRequest) Validate() *SpotifeteError {
+	spotifeteError := r.GuestRequest.Validate()
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	if "up" != r.Vote && "down" != r.Vote {
+		return NewUserError("Parameter vote must be either up or down.")
+	}
+
+	return nil
+}
+
+type RemoveVoteRequest struct {
+	GuestRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+}
+
+func (r RemoveVoteRequest) Validate() *SpotifeteError {
+	spotifeteError := r.GuestRequest.Validate()
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	return nil
+}
+
+type SetQueueStrategyRequest struct {
+	AuthenticatedRequest
+	QueueStrategy string `json:"queue_strategy"`
+}
+
+func (r SetQueueStrategyRequest) Validate() *SpotifeteError {
+	if "" == r.QueueStrategy {
+		return NewUserError("Missing parameter queue_strategy.")
+	}
+
+	return nil
+}
+
+type SetPlaybackBackendRequest struct {
+	AuthenticatedRequest
+	PlaybackBackend string `json:"playback_backend"`
+}
+
+func (r SetPlaybackBackendRequest) Validate() *SpotifeteError {
+	if "" == r.PlaybackBackend {
+		return NewUserError("Missing parameter playback_backend.")
+	}
+
+	return nil
+}
+
+type SetQueueLookaheadRequest struct {
+	AuthenticatedRequest
+	QueueLookahead uint `json:"queue_lookahead"`
+}
+
+func (r SetQueueLookaheadRequest) Validate() *SpotifeteError {
+	if r.QueueLookahead == 0 {
+		return NewUserError("Missing parameter queue_lookahead.")
+	}
+
+	return nil
+}
+
+type SetRequestLimitsRequest struct {
+	AuthenticatedRequest
+	MaxUnplayedRequests       uint `json:"max_unplayed_requests"`
+	MaxRequestsPerWindow      uint `json:"max_requests_per_window"`
+	RequestWindowMinutes      uint `json:"request_window_minutes"`
+	MinSecondsBetweenRequests uint `json:"min_seconds_between_requests"`
+}
+
+type SetContentFiltersRequest struct {
+	AuthenticatedRequest
+	AllowExplicit           *bool `json:"allow_explicit"`
+	MaxTrackDurationSeconds uint  `json:"max_track_duration_seconds"`
+}
+
+func (r SetContentFiltersRequest) Validate() *SpotifeteError {
+	if r.AllowExplicit == nil {
+		return NewUserError("Missing parameter allow_explicit.")
+	}
+
+	return nil
+}
+
+type SetModeratedRequest struct {
+	AuthenticatedRequest
+	Moderated bool `json:"moderated"`
+}
+
+type ApproveRequestRequest struct {
+	AuthenticatedRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+}
+
+func (r ApproveRequestRequest) Validate() *SpotifeteError {
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	return nil
+}
+
+type RejectRequestRequest struct {
+	AuthenticatedRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+	Reason         string `json:"reason"`
+}
+
+func (r RejectRequestRequest) Validate() *SpotifeteError {
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	return nil
+}
+
+type SessionRuleRequest struct {
+	AuthenticatedRequest
+	RuleType  string `json:"rule_type"`
+	Subject   string `json:"subject"`
+	SpotifyId string `json:"spotify_id"`
+}
+
+func (r SessionRuleRequest) Validate() *SpotifeteError {
+	if "" == r.RuleType {
+		return NewUserError("Missing parameter rule_type.")
+	}
+
+	if "" == r.Subject {
+		return NewUserError("Missing parameter subject.")
+	}
+
+	if "" == r.SpotifyId {
+		return NewUserError("Missing parameter spotify_id.")
+	}
+
+	return nil
+}
+
+type MoveRequestRequest struct {
+	AuthenticatedRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+	Position       int    `json:"position"`
+}
+
+func (r MoveRequestRequest) Validate() *SpotifeteError {
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	if r.Position < 1 {
+		return NewUserError("Parameter position must be at least 1.")
+	}
+
+	return nil
+}
+
+type PlayNextRequest struct {
+	AuthenticatedRequest
+	SpotifyTrackId string `json:"spotify_track_id"`
+}
+
+func (r PlayNextRequest) Validate() *SpotifeteError {
+	if "" == r.SpotifyTrackId {
+		return NewUserError("Missing parameter spotify_track_id.")
+	}
+
+	return nil
+}
+
+type SeekRequest struct {
+	AuthenticatedRequest
+	PositionMs int `json:"position_ms"`
+}
+
+func (r SeekRequest) Validate() *SpotifeteError {
+	if r.PositionMs < 0 {
+		return NewUserError("Parameter position_ms must not be negative.")
+	}
+
+	return nil
+}
+
+type SetVolumeRequest struct {
+	AuthenticatedRequest
+	VolumePercent *int `json:"volume_percent"`
+}
+
+func (r SetVolumeRequest) Validate() *SpotifeteError {
+	if r.VolumePercent == nil {
+		return NewUserError("Missing parameter volume_percent.")
+	}
+
+	if *r.VolumePercent < 0 || *r.VolumePercent > 100 {
+		return NewUserError("Parameter volume_percent must be between 0 and 100.")
+	}
+
+	return nil
+}
+
+type StartPlaybackRequest struct {
+	AuthenticatedRequest
+	DeviceId string `json:"device_id"`
+}
+
+func (r StartPlaybackRequest) Validate() *SpotifeteError {
+	if "" == r.DeviceId {
+		return NewUserError("Missing parameter device_id.")
+	}
+
+	return nil
+}
+
+type SetAutoRepairPlaybackRequest struct {
+	AuthenticatedRequest
+	AutoRepairPlayback bool `json:"auto_repair_playback"`
+}
+
+type SetMarketRequest struct {
+	AuthenticatedRequest
+	// Two letter country code, empty to use the country of the owner
+	Market string `json:"market"`
+}
+
+type AddWebhookRequest struct {
+	AuthenticatedRequest
+	Url    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+func (r AddWebhookRequest) Validate() *SpotifeteError {
+	if "" == r.Url {
+		return NewUserError("Missing parameter url.")
+	}
+
+	if len(r.Events) == 0 {
+		return NewUserError("Missing parameter events.")
+	}
+
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"time"
 
 	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	"github.com/zmb3/spotify"
 )
 
 type SearchTracksResponse struct {
-	Query  string                `json:"query"`
-	Tracks []model.TrackMetadata `json:"tracks"`
+	Query  string                               `json:"query"`
+	Tracks []listeningSession.TrackSearchResult `json:"tracks"`
 }
 
 type SearchPlaylistResponse struct {
@@ -16,10 +18,105 @@ type SearchPlaylistResponse struct {
 	Playlists []model.PlaylistMetadata `json:"playlists"`
 }
 
+type QueueEntryResponse struct {
+	model.SongRequest
+	EstimatedStartTime *time.Time `json:"estimated_start_time"`
+}
+
 type GetSessionQueueResponse struct {
-	Queue []model.SongRequest `json:"queue"`
+	Queue []QueueEntryResponse `json:"queue"`
+}
+
+type GetPlayHistoryResponse struct {
+	History    []model.SongRequest `json:"history"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalCount int64               `json:"total_count"`
 }
 
 type QueueLastUpdatedResponse struct {
 	QueueLastUpdated time.Time `json:"queue_last_updated"`
 }
+
+type QueueStrategyResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GetQueueStrategiesResponse struct {
+	QueueStrategies []QueueStrategyResponse `json:"queue_strategies"`
+}
+
+type PlaybackBackendResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GetPlaybackBackendsResponse struct {
+	PlaybackBackends []PlaybackBackendResponse `json:"playback_backends"`
+}
+
+type JoinSessionResponse struct {
+	GuestToken string      `json:"guest_token"`
+	Guest      model.Guest `json:"guest"`
+}
+
+type GetGuestRequestsResponse struct {
+	Guest    model.Guest         `json:"guest"`
+	Requests []model.SongRequest `json:"requests"`
+}
+
+type GetPendingRequestsResponse struct {
+	Requests []model.SongRequest `json:"requests"`
+}
+
+type GetSessionRulesResponse struct {
+	Rules []model.SessionRule `json:"rules"`
+}
+
+type GetPlaybackDevicesResponse struct {
+	Devices []spotify.PlayerDevice `json:"devices"`
+}
+
+type GetSessionStatusResponse struct {
+	PlaybackState      string     `json:"playback_state"`
+	PlaybackStateSince *time.Time `json:"playback_state_since"`
+	Description        string     `json:"description"`
+	AutoRepairPlayback bool       `json:"auto_repair_playback"`
+}
+
+type WebhookResponse struct {
+	Id        uint      `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (WebhookResponse) FromWebhook(webhook model.Webhook) WebhookResponse {
+	return WebhookResponse{
+		Id:        webhook.ID,
+		Url:       webhook.Url,
+		Events:    webhook.EventList(),
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+type GetWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// The secret is only shown once, right after the webhook was added
+type AddWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	Id        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	model.WebhookDelivery
+}
+
+type GetWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
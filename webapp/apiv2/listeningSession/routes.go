@@ -8,11 +8,27 @@ func SetupRoutes(baseRouterGroup *gin.RouterGroup) {
 	router := baseRouterGroup.Group("/session")
 
 	router.POST("/new", newSession)
+	router.GET("/queue-strategies", getQueueStrategies)
+	router.GET("/playback-backends", getPlaybackBackends)
+	router.GET("/search/cache-stats", getSearchCacheStats)
 	router.GET("/id/:joinId", getSession)
 	router.DELETE("/id/:joinId", closeSession)
+	router.GET("/id/:joinId/status", getSessionStatus)
+	router.GET("/id/:joinId/events", sessionEvents)
+	router.POST("/id/:joinId/join", joinSession)
+	router.GET("/id/:joinId/my-requests", getGuestRequests)
 	router.GET("/id/:joinId/queue", getSessionQueue)
+	router.GET("/id/:joinId/now-playing", getNowPlaying)
+	router.GET("/id/:joinId/history", getPlayHistory)
 	router.DELETE("/id/:joinId/queue", deleteRequestFromQueue)
 	router.GET("/id/:joinId/queue/last-updated", queueLastUpdated)
+	router.PUT("/id/:joinId/queue/vote", voteForRequest)
+	router.DELETE("/id/:joinId/queue/vote", removeVoteFromRequest)
+	router.GET("/id/:joinId/queue/pending", getPendingRequests)
+	router.POST("/id/:joinId/queue/pending/approve", approveRequest)
+	router.POST("/id/:joinId/queue/pending/reject", rejectRequest)
+	router.PATCH("/id/:joinId/queue/position", moveRequest)
+	router.POST("/id/:joinId/queue/play-next", playNext)
 	router.GET("/id/:joinId/qrcode", qrCode)
 	router.GET("/id/:joinId/search/track", searchTrack)
 	router.GET("/id/:joinId/search/playlist", searchPlaylist)
@@ -22,4 +38,26 @@ func SetupRoutes(baseRouterGroup *gin.RouterGroup) {
 	router.PUT("/id/:joinId/fallback-playlist", changeFallbackPlaylist)
 	router.DELETE("/id/:joinId/fallback-playlist", removeFallbackPlaylist)
 	router.PATCH("/id/:joinId/fallback-playlist/shuffle", setFallbackPlaylistShuffle)
+	router.PATCH("/id/:joinId/queue-strategy", setQueueStrategy)
+	router.PATCH("/id/:joinId/playback-backend", setPlaybackBackend)
+	router.PATCH("/id/:joinId/queue-lookahead", setQueueLookahead)
+	router.PATCH("/id/:joinId/request-limits", setRequestLimits)
+	router.PATCH("/id/:joinId/content-filters", setContentFilters)
+	router.PATCH("/id/:joinId/moderation", setModerated)
+	router.PATCH("/id/:joinId/auto-repair-playback", setAutoRepairPlayback)
+	router.PATCH("/id/:joinId/market", setMarket)
+	router.GET("/id/:joinId/rules", getSessionRules)
+	router.POST("/id/:joinId/rules", addSessionRule)
+	router.DELETE("/id/:joinId/rules", removeSessionRule)
+	router.GET("/id/:joinId/webhooks", getWebhooks)
+	router.POST("/id/:joinId/webhooks", addWebhook)
+	router.DELETE("/id/:joinId/webhooks/:webhookId", removeWebhook)
+	router.GET("/id/:joinId/webhooks/:webhookId/deliveries", getWebhookDeliveries)
+	router.GET("/id/:joinId/player/devices", getPlaybackDevices)
+	router.POST("/id/:joinId/player/start", startPlayback)
+	router.POST("/id/:joinId/player/skip", skipTrack)
+	router.POST("/id/:joinId/player/pause", pausePlayback)
+	router.POST("/id/:joinId/player/resume", resumePlayback)
+	router.PUT("/id/:joinId/player/seek", seekPlayback)
+	router.PUT("/id/:joinId/player/volume", setVolume)
 }
@@ -0,0 +1,187 @@
+package listeningSession
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/webapp/apiv2/shared"
+	"github.com/partyoffice/spotifete/webhooks"
+)
+
+func getWebhooks(c *gin.Context) {
+	loginSessionId := c.Query("loginSessionId")
+	if loginSessionId == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Missing query parameter 'loginSessionId'."})
+		return
+	}
+
+	authenticatedUser, spotifeteError := AuthenticatedRequest{LoginSessionId: loginSessionId}.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	sessionWebhooks, spotifeteError := webhooks.GetWebhooks(*session, authenticatedUser)
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	var webhookResponses []WebhookResponse
+	for _, webhook := range sessionWebhooks {
+		webhookResponses = append(webhookResponses, WebhookResponse{}.FromWebhook(webhook))
+	}
+
+	c.JSON(http.StatusOK, GetWebhooksResponse{Webhooks: webhookResponses})
+}
+
+func addWebhook(c *gin.Context) {
+	request := AddWebhookRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid requestBody: " + err.Error()})
+		return
+	}
+
+	spotifeteError := request.Validate()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	authenticatedUser, spotifeteError := request.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	webhook, spotifeteError := webhooks.AddWebhook(*session, authenticatedUser, request.Url, request.Events)
+	if spotifeteError == nil {
+		c.JSON(http.StatusOK, AddWebhookResponse{
+			WebhookResponse: WebhookResponse{}.FromWebhook(webhook),
+			Secret:          webhook.Secret,
+		})
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
+}
+
+func removeWebhook(c *gin.Context) {
+	request := AuthenticatedRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "invalid requestBody: " + err.Error()})
+		return
+	}
+
+	authenticatedUser, spotifeteError := request.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	webhookId, err := strconv.ParseUint(c.Param("webhookId"), 10, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid webhook id."})
+		return
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	spotifeteError = webhooks.RemoveWebhook(*session, authenticatedUser, uint(webhookId))
+	if spotifeteError == nil {
+		c.Status(http.StatusNoContent)
+	} else {
+		SetJsonError(*spotifeteError, c)
+	}
+}
+
+func getWebhookDeliveries(c *gin.Context) {
+	loginSessionId := c.Query("loginSessionId")
+	if loginSessionId == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Missing query parameter 'loginSessionId'."})
+		return
+	}
+
+	authenticatedUser, spotifeteError := AuthenticatedRequest{LoginSessionId: loginSessionId}.GetSimpleUser()
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	webhookId, err := strconv.ParseUint(c.Param("webhookId"), 10, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid webhook id."})
+		return
+	}
+
+	limitParameter := c.Query("limit")
+	var limit = webhooks.DefaultDeliveriesLimit
+	if len(limitParameter) > 0 {
+		parsedLimit, err := strconv.ParseUint(limitParameter, 10, 0)
+		if err == nil {
+			limit = int(parsedLimit)
+		} else {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid limit."})
+			return
+		}
+	}
+
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Listening session not found."})
+		return
+	}
+
+	deliveries, spotifeteError := webhooks.GetWebhookDeliveries(*session, authenticatedUser, uint(webhookId), limit)
+	if spotifeteError != nil {
+		SetJsonError(*spotifeteError, c)
+		return
+	}
+
+	var deliveryResponses []WebhookDeliveryResponse
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, WebhookDeliveryResponse{
+			Id:              delivery.ID,
+			CreatedAt:       delivery.CreatedAt,
+			WebhookDelivery: delivery,
+		})
+	}
+
+	c.JSON(http.StatusOK, GetWebhookDeliveriesResponse{Deliveries: deliveryResponses})
+}
@@ -3,6 +3,8 @@ package webapp
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,11 +12,16 @@ import (
 	"github.com/partyoffice/spotifete/config"
 	"github.com/partyoffice/spotifete/database/model"
 	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/shared"
 	"github.com/partyoffice/spotifete/users"
+	"github.com/zmb3/spotify"
 )
 
 type TemplateController struct{}
 
+// Number of played tracks shown on the session page
+const recentlyPlayedCount = 10
+
 func (c TemplateController) SetupWithBaseRouter(baseRouter *gin.Engine) {
 	baseRouter.LoadHTMLGlob("resources/templates/*.html")
 
@@ -24,8 +31,21 @@ func (c TemplateController) SetupWithBaseRouter(baseRouter *gin.Engine) {
 	baseRouter.GET("/session/new", c.NewListeningSession)
 	baseRouter.POST("/session/new", c.NewListeningSessionSubmit)
 	baseRouter.GET("/session/view/:joinId", c.ViewSession)
+	baseRouter.POST("/session/view/:joinId/join", c.JoinListeningSession)
 	baseRouter.POST("/session/view/:joinId/request", c.RequestTrack)
 	baseRouter.POST("/session/view/:joinId/fallback", c.ChangeFallbackPlaylist)
+	baseRouter.POST("/session/view/:joinId/queue-strategy", c.SetQueueStrategy)
+	baseRouter.POST("/session/view/:joinId/playback-backend", c.SetPlaybackBackend)
+	baseRouter.POST("/session/view/:joinId/queue-lookahead", c.SetQueueLookahead)
+	baseRouter.POST("/session/view/:joinId/request-limits", c.SetRequestLimits)
+	baseRouter.POST("/session/view/:joinId/content-filters", c.SetContentFilters)
+	baseRouter.POST("/session/view/:joinId/moderation", c.SetModerated)
+	baseRouter.POST("/session/view/:joinId/auto-repair-playback", c.SetAutoRepairPlayback)
+	baseRouter.POST("/session/view/:joinId/market", c.SetMarket)
+	baseRouter.POST("/session/view/:joinId/pending/approve", c.ApproveRequest)
+	baseRouter.POST("/session/view/:joinId/pending/reject", c.RejectRequest)
+	baseRouter.POST("/session/view/:joinId/queue/move", c.MoveRequest)
+	baseRouter.POST("/session/view/:joinId/player/:action", c.PlayerAction)
 	baseRouter.POST("/session/close", c.CloseListeningSession)
 	baseRouter.GET("/app", c.GetApp)
 	baseRouter.GET("/app/android", c.GetAppAndroid)
@@ -137,18 +157,114 @@ func (TemplateController) ViewSession(c *gin.Context) {
 			"displayError": err.Error(),
 		})
 	}
-	queueLastUpdated := listeningSession.GetQueueLastUpdated(*session).UTC().Format(time.RFC3339Nano)
+
+	guest := getGuestFromCookie(c, *session)
+	var guestRequests []model.SongRequest
+	if guest != nil {
+		guestRequests, _ = listeningSession.GetQueuedRequestsOfGuest(*session, *guest)
+	}
+
+	var pendingRequests []model.SongRequest
+	var devices []spotify.PlayerDevice
+	var ownerCountry string
+	if user != nil && user.ID == session.OwnerId {
+		ownerCountry = user.Country
+		pendingRequests, _ = listeningSession.GetPendingRequests(*session, *user)
+
+		fullSession := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+			JoinId: joinId,
+			Active: true,
+		})
+		if fullSession != nil {
+			devices, _ = listeningSession.GetPlaybackDevices(*fullSession, *user)
+		}
+	}
+
+	recentlyPlayed, _, _ := listeningSession.GetPlayHistory(*session, 1, recentlyPlayedCount)
 
 	displayError := c.Query("displayError")
 	c.HTML(http.StatusOK, "viewSession.html", gin.H{
-		"queueLastUpdated": queueLastUpdated,
 		"session":          session,
 		"queue":            fullQueue,
+		"recentlyPlayed":   recentlyPlayed,
+		"queueLastUpdated": listeningSession.GetQueueLastUpdated(*session).UnixMilli(),
+		"queueStrategies":  listeningSession.QueueStrategies(),
+		"playbackBackends": listeningSession.PlaybackBackends(),
+		"minLookahead":     listeningSession.MinQueueLookahead,
+		"maxLookahead":     listeningSession.MaxQueueLookahead,
 		"user":             user,
+		"guest":            guest,
+		"guestRequests":    guestRequests,
+		"pendingRequests":  pendingRequests,
+		"devices":          devices,
+		"ownerCountry":     ownerCountry,
+		"playbackStatus":   listeningSession.DescribePlaybackState(session.PlaybackState),
 		"displayError":     displayError,
 	})
 }
 
+func (TemplateController) JoinListeningSession(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	existingGuestToken := ""
+	if guestToken := authentication.GetGuestTokenFromCookie(c); guestToken != nil {
+		existingGuestToken = *guestToken
+	}
+
+	_, guestToken, spotifeteError := listeningSession.JoinSession(*session, c.PostForm("displayName"), existingGuestToken, c.ClientIP())
+	if spotifeteError != nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+		return
+	}
+
+	authentication.SetGuestCookie(c, joinId, guestToken)
+	c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+}
+
+func getGuestFromCookie(c *gin.Context, session model.SimpleListeningSession) *model.Guest {
+	guestToken := authentication.GetGuestTokenFromCookie(c)
+	if guestToken == nil {
+		return nil
+	}
+
+	guest, spotifeteError := listeningSession.FindGuestForToken(session, *guestToken)
+	if spotifeteError != nil {
+		authentication.RemoveGuestCookie(c, session.JoinId)
+		return nil
+	}
+
+	return guest
+}
+
+// Logged in users automatically join with their Spotify display name
+func getOrCreateGuest(c *gin.Context, session model.SimpleListeningSession) (*model.Guest, *SpotifeteError) {
+	guest := getGuestFromCookie(c, session)
+	if guest != nil {
+		return guest, nil
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		return nil, NewUserError("Please choose a name before requesting tracks.")
+	}
+
+	newGuest, guestToken, spotifeteError := listeningSession.JoinSession(session, loginSession.User.SpotifyDisplayName, "", c.ClientIP())
+	if spotifeteError != nil {
+		return nil, spotifeteError
+	}
+
+	authentication.SetGuestCookie(c, session.JoinId, guestToken)
+	return &newGuest, nil
+}
+
 func (TemplateController) RequestTrack(c *gin.Context) {
 	joinId := c.Param("joinId")
 	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
@@ -162,13 +278,13 @@ func (TemplateController) RequestTrack(c *gin.Context) {
 
 	trackId := c.PostForm("trackId")
 
-	username := ""
-	loginSession := authentication.GetValidSessionFromCookie(c)
-	if loginSession != nil && loginSession.User != nil {
-		username = loginSession.User.SpotifyDisplayName
+	guest, spotifeteError := getOrCreateGuest(c, session.SimpleListeningSession)
+	if spotifeteError != nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+		return
 	}
 
-	_, spotifeteError := listeningSession.RequestSong(*session, trackId, username)
+	_, spotifeteError = listeningSession.RequestSong(*session, trackId, *guest)
 	if spotifeteError == nil {
 		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
 	} else {
@@ -202,6 +318,380 @@ func (TemplateController) ChangeFallbackPlaylist(c *gin.Context) {
 	}
 }
 
+func (TemplateController) SetQueueStrategy(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	queueStrategy := c.PostForm("queueStrategy")
+	spotifeteError := listeningSession.SetQueueStrategy(*session, *loginSession.User, queueStrategy)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetPlaybackBackend(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	playbackBackend := c.PostForm("playbackBackend")
+	spotifeteError := listeningSession.SetPlaybackBackend(*session, *loginSession.User, playbackBackend)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetQueueLookahead(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	queueLookahead, err := parseUintFormValue(c, "queueLookahead")
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, "The queue lookahead must be a positive number."))
+		return
+	}
+
+	spotifeteError := listeningSession.SetQueueLookahead(*session, *loginSession.User, queueLookahead)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetRequestLimits(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	limits := model.RequestLimits{}
+	var err error
+	for formKey, limit := range map[string]*uint{
+		"maxUnplayedRequests":       &limits.MaxUnplayedRequests,
+		"maxRequestsPerWindow":      &limits.MaxRequestsPerWindow,
+		"requestWindowMinutes":      &limits.RequestWindowMinutes,
+		"minSecondsBetweenRequests": &limits.MinSecondsBetweenRequests,
+	} {
+		*limit, err = parseUintFormValue(c, formKey)
+		if err != nil {
+			c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, "Request limits must be positive numbers."))
+			return
+		}
+	}
+
+	spotifeteError := listeningSession.SetRequestLimits(*session, *loginSession.User, limits)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetContentFilters(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	maxTrackDurationSeconds, err := parseUintFormValue(c, "maxTrackDurationSeconds")
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, "Maximum track length must be a positive number."))
+		return
+	}
+
+	spotifeteError := listeningSession.SetContentFilters(*session, *loginSession.User, model.ContentFilters{
+		AllowExplicit:           c.PostForm("allowExplicit") == "on",
+		MaxTrackDurationSeconds: maxTrackDurationSeconds,
+	})
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetModerated(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	moderated := c.PostForm("moderated") == "on"
+	spotifeteError := listeningSession.SetModerated(*session, *loginSession.User, moderated)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetAutoRepairPlayback(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	autoRepair := c.PostForm("autoRepairPlayback") == "on"
+	spotifeteError := listeningSession.SetAutoRepairPlayback(*session, *loginSession.User, autoRepair)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) SetMarket(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	spotifeteError := listeningSession.SetMarket(*session, *loginSession.User, c.PostForm("market"))
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) ApproveRequest(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	spotifeteError := listeningSession.ApproveRequest(*session, *loginSession.User, c.PostForm("trackId"))
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) RejectRequest(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindSimpleListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	spotifeteError := listeningSession.RejectRequest(*session, *loginSession.User, c.PostForm("trackId"), c.PostForm("reason"))
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) MoveRequest(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	currentPosition, err := strconv.Atoi(c.PostForm("currentPosition"))
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, "Invalid queue position."))
+		return
+	}
+
+	var newPosition int
+	switch c.PostForm("direction") {
+	case "up":
+		newPosition = currentPosition - 1
+	case "down":
+		newPosition = currentPosition + 1
+	default:
+		newPosition = 1
+	}
+
+	spotifeteError := listeningSession.MoveRequest(*session, *loginSession.User, c.PostForm("trackId"), newPosition)
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func (TemplateController) PlayerAction(c *gin.Context) {
+	joinId := c.Param("joinId")
+	session := listeningSession.FindFullListeningSession(model.SimpleListeningSession{
+		JoinId: joinId,
+		Active: true,
+	})
+	if session == nil {
+		c.String(http.StatusNotFound, "session not found")
+		return
+	}
+
+	loginSession := authentication.GetValidSessionFromCookie(c)
+	if loginSession == nil || loginSession.User == nil {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/login?redirectTo=/session/view/%s", joinId))
+		return
+	}
+
+	var spotifeteError *SpotifeteError
+	switch c.Param("action") {
+	case "start":
+		spotifeteError = listeningSession.StartPlayback(*session, *loginSession.User, c.PostForm("deviceId"))
+	case "skip":
+		spotifeteError = listeningSession.SkipTrack(*session, *loginSession.User)
+	case "pause":
+		spotifeteError = listeningSession.PausePlayback(*session, *loginSession.User)
+	case "resume":
+		spotifeteError = listeningSession.ResumePlayback(*session, *loginSession.User)
+	default:
+		c.String(http.StatusNotFound, "unknown player action")
+		return
+	}
+
+	if spotifeteError == nil {
+		c.Redirect(http.StatusSeeOther, "/session/view/"+joinId)
+	} else {
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/session/view/%s/?displayError=%s", joinId, spotifeteError.MessageForUser))
+	}
+}
+
+func parseUintFormValue(c *gin.Context, key string) (uint, error) {
+	value := strings.TrimSpace(c.PostForm(key))
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	parsedValue, err := strconv.ParseUint(value, 10, 0)
+	return uint(parsedValue), err
+}
+
 func (TemplateController) CloseListeningSession(c *gin.Context) {
 	joinId := c.PostForm("joinId")
 	if len(joinId) == 0 {
@@ -0,0 +1,254 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/logger"
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	"github.com/partyoffice/spotifete/jobs"
+	"github.com/partyoffice/spotifete/listeningSession"
+	. "github.com/partyoffice/spotifete/shared"
+	"gorm.io/gorm"
+)
+
+// Deliveries run as jobs, so they are retried with the backoff of the job queue and survive restarts.
+// A delivery is given up after this many attempts, before the job queue would give up on the job itself.
+const (
+	jobDeliverWebhook   = "DELIVER_WEBHOOK"
+	maxDeliveryAttempts = 5
+)
+
+type deliveryJobPayload struct {
+	DeliveryId uint `json:"delivery_id"`
+}
+
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		// No proxy, the address the client connects to has to be the one of the webhook
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			// Runs after the host name was resolved, right before connecting. Checking the address here instead of when
+			// the webhook is added means a DNS record that changes later, or a redirect, can not reach a forbidden address.
+			Control: checkWebhookConnection,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     time.Minute,
+	},
+}
+
+type Payload struct {
+	Event   string             `json:"event"`
+	JoinId  string             `json:"join_id"`
+	Time    time.Time          `json:"time"`
+	Request *model.SongRequest `json:"request,omitempty"`
+}
+
+// Has to be called after listeningSession.Setup
+func Setup() {
+	jobs.Register(jobDeliverWebhook, deliver)
+
+	listeningSession.Subscribe(func(event listeningSession.SongRequested) {
+		// Requests of moderated sessions are only announced once the owner approved them
+		if event.UpvotedDuplicate || event.Request.ApprovalStatus != model.ApprovalStatusApproved {
+			return
+		}
+
+		request := event.Request
+		notifyWebhooks(event.Session.SimpleListeningSession, model.WebhookEventRequestCreated, &request)
+	})
+	listeningSession.Subscribe(func(event listeningSession.RequestApproved) {
+		request := event.Request
+		notifyWebhooks(event.Session.SimpleListeningSession, model.WebhookEventRequestCreated, &request)
+	})
+	listeningSession.Subscribe(func(event listeningSession.QueueAdvanced) {
+		if len(event.Queue) == 0 {
+			return
+		}
+
+		startedRequest := event.Queue[0]
+		notifyWebhooks(event.Session.SimpleListeningSession, model.WebhookEventTrackStarted, &startedRequest)
+	})
+	listeningSession.Subscribe(func(event listeningSession.SessionClosed) {
+		notifyWebhooks(event.Session.SimpleListeningSession, model.WebhookEventSessionClosed, nil)
+	})
+}
+
+func notifyWebhooks(session model.SimpleListeningSession, event string, request *model.SongRequest) {
+
+	webhooks, err := findWebhooksOfSession(session)
+	if err != nil {
+		NewInternalError("could not fetch webhooks to notify", err)
+		return
+	}
+
+	payload := Payload{
+		Event:   event,
+		JoinId:  session.JoinId,
+		Time:    time.Now(),
+		Request: request,
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.IsSubscribedTo(event) {
+			enqueueDelivery(webhook, payload)
+		}
+	}
+}
+
+func enqueueDelivery(webhook model.Webhook, payload Payload) {
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		NewInternalError("could not serialize webhook payload", err)
+		return
+	}
+
+	enqueueDeliveryTask := func(tx *gorm.DB) error {
+		delivery := model.WebhookDelivery{
+			WebhookId: webhook.ID,
+			Event:     payload.Event,
+			Payload:   string(body),
+			Status:    model.WebhookDeliveryStatusPending,
+		}
+		err := tx.Create(&delivery).Error
+		if err != nil {
+			return err
+		}
+
+		return jobs.EnqueueInTransaction(jobDeliverWebhook, deliveryJobPayload{DeliveryId: delivery.ID}, tx)
+	}
+
+	err = database.GetConnection().Transaction(enqueueDeliveryTask)
+	if err != nil {
+		NewInternalError("could not save webhook delivery", err)
+	}
+}
+
+// Returns an error to have the job retried
+func deliver(payload deliveryJobPayload) error {
+
+	var delivery model.WebhookDelivery
+	err := database.GetConnection().First(&delivery, payload.DeliveryId).Error
+	if err != nil {
+		return fmt.Errorf("could not fetch webhook delivery %d: %w", payload.DeliveryId, err)
+	}
+	if delivery.Status != model.WebhookDeliveryStatusPending {
+		return nil
+	}
+
+	// The owner might have removed the webhook in the meantime
+	var webhooks []model.Webhook
+	err = database.GetConnection().Where("id = ?", delivery.WebhookId).Find(&webhooks).Error
+	if err != nil {
+		return fmt.Errorf("could not fetch webhook %d: %w", delivery.WebhookId, err)
+	}
+	if len(webhooks) == 0 {
+		setDeliveryError(&delivery, "Webhook was removed.")
+		updateDeliveryStatus(&delivery, model.WebhookDeliveryStatusFailed)
+		return nil
+	}
+
+	if attemptDelivery(webhooks[0], &delivery) {
+		return nil
+	}
+
+	if delivery.Attempts >= maxDeliveryAttempts {
+		logger.Warningf("Giving up on webhook delivery %d after %d attempts.", delivery.ID, delivery.Attempts)
+		updateDeliveryStatus(&delivery, model.WebhookDeliveryStatusFailed)
+		return nil
+	}
+
+	return fmt.Errorf("webhook delivery %d failed: %s", delivery.ID, *delivery.Error)
+}
+
+func attemptDelivery(webhook model.Webhook, delivery *model.WebhookDelivery) (delivered bool) {
+
+	delivery.Attempts++
+	defer database.GetConnection().Save(delivery)
+
+	request, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		setDeliveryError(delivery, err.Error())
+		return false
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "SpotiFete-Webhook")
+	request.Header.Set("X-SpotiFete-Event", delivery.Event)
+	request.Header.Set("X-SpotiFete-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set("X-SpotiFete-Signature", "sha256="+sign(webhook.Secret, delivery.Payload))
+
+	response, err := webhookClient.Do(request)
+	if err != nil {
+		setDeliveryError(delivery, err.Error())
+		return false
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	responseStatus := response.StatusCode
+	delivery.ResponseStatus = &responseStatus
+
+	if responseStatus < 200 || responseStatus >= 300 {
+		setDeliveryError(delivery, fmt.Sprintf("Webhook responded with status %d.", responseStatus))
+		return false
+	}
+
+	now := time.Now()
+	delivery.Status = model.WebhookDeliveryStatusDelivered
+	delivery.DeliveredAt = &now
+	delivery.Error = nil
+
+	return true
+}
+
+func checkWebhookConnection(_ string, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || isForbiddenWebhookAddress(ip) {
+		return fmt.Errorf("webhook must not connect to local or private address %s", host)
+	}
+
+	return nil
+}
+
+// Receivers can verify the payload by computing the HMAC-SHA256 of the request body with their secret
+func sign(secret string, payload string) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func setDeliveryError(delivery *model.WebhookDelivery, message string) {
+	delivery.Error = &message
+}
+
+func updateDeliveryStatus(delivery *model.WebhookDelivery, status string) {
+
+	delivery.Status = status
+	err := database.GetConnection().Save(delivery).Error
+	if err != nil {
+		NewInternalError("could not update webhook delivery status", err)
+	}
+}
@@ -0,0 +1,231 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/partyoffice/spotifete/database"
+	"github.com/partyoffice/spotifete/database/model"
+	. "github.com/partyoffice/spotifete/shared"
+)
+
+const (
+	maxWebhooksPerSession  = 10
+	DefaultDeliveriesLimit = 50
+	MaxDeliveriesLimit     = 100
+)
+
+var webhookEvents = []string{
+	model.WebhookEventRequestCreated,
+	model.WebhookEventTrackStarted,
+	model.WebhookEventSessionClosed,
+}
+
+func GetWebhooks(session model.SimpleListeningSession, user model.SimpleUser) ([]model.Webhook, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return nil, NewUserError("Only the session owner can see the webhooks.")
+	}
+
+	webhooks, err := findWebhooksOfSession(session)
+	if err != nil {
+		return nil, NewInternalError("Could not fetch webhooks from database", err)
+	}
+
+	return webhooks, nil
+}
+
+func findWebhooksOfSession(session model.SimpleListeningSession) ([]model.Webhook, error) {
+
+	var webhooks []model.Webhook
+	err := database.GetConnection().Where(model.Webhook{SessionId: session.ID}).Order("created_at asc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func findWebhookOfSession(session model.SimpleListeningSession, webhookId uint) (*model.Webhook, *SpotifeteError) {
+
+	var webhooks []model.Webhook
+	err := database.GetConnection().Where("id = ? AND session_id = ?", webhookId, session.ID).Find(&webhooks).Error
+	if err != nil {
+		return nil, NewInternalError("Could not fetch webhook from database", err)
+	}
+
+	if len(webhooks) == 0 {
+		return nil, NewUserError("Webhook not found.")
+	}
+
+	return &webhooks[0], nil
+}
+
+// The returned webhook contains the secret the payloads are signed with. It is not shown again later.
+func AddWebhook(session model.SimpleListeningSession, user model.SimpleUser, webhookUrl string, events []string) (model.Webhook, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return model.Webhook{}, NewUserError("Only the session owner can add webhooks.")
+	}
+
+	spotifeteError := validateWebhookUrl(webhookUrl)
+	if spotifeteError != nil {
+		return model.Webhook{}, spotifeteError
+	}
+
+	cleanedEvents, spotifeteError := cleanWebhookEvents(events)
+	if spotifeteError != nil {
+		return model.Webhook{}, spotifeteError
+	}
+
+	existingWebhooks, err := findWebhooksOfSession(session)
+	if err != nil {
+		return model.Webhook{}, NewInternalError("Could not fetch webhooks from database", err)
+	}
+	if len(existingWebhooks) >= maxWebhooksPerSession {
+		return model.Webhook{}, NewUserError(fmt.Sprintf("A session can not have more than %d webhooks.", maxWebhooksPerSession))
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return model.Webhook{}, NewInternalError("Could not generate webhook secret", err)
+	}
+
+	newWebhook := model.Webhook{
+		SessionId: session.ID,
+		Url:       webhookUrl,
+		Secret:    secret,
+		Events:    strings.Join(cleanedEvents, ","),
+	}
+
+	err = database.GetConnection().Create(&newWebhook).Error
+	if err != nil {
+		return model.Webhook{}, NewInternalError("could not save new webhook", err)
+	}
+
+	return newWebhook, nil
+}
+
+func validateWebhookUrl(webhookUrl string) *SpotifeteError {
+
+	if len(webhookUrl) > 2048 {
+		return NewUserError("Webhook URL must not be longer than 2048 characters.")
+	}
+
+	parsedUrl, err := url.Parse(webhookUrl)
+	if err != nil || parsedUrl.Host == "" || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") {
+		return NewUserError("Webhook URL must be an absolute http or https URL.")
+	}
+
+	// Only a hint for the owner. The addresses are checked again on every delivery, because the DNS records can change.
+	addresses, err := net.LookupIP(parsedUrl.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return NewUserError("The host of the webhook URL could not be resolved.")
+	}
+	for _, address := range addresses {
+		if isForbiddenWebhookAddress(address) {
+			return NewUserError("Webhook URL must not point to a local or private address.")
+		}
+	}
+
+	return nil
+}
+
+// Webhooks must not reach into the network SpotiFete runs in, e.g. the database or cloud metadata endpoints
+func isForbiddenWebhookAddress(address net.IP) bool {
+	return address.IsLoopback() ||
+		address.IsPrivate() ||
+		address.IsUnspecified() ||
+		address.IsLinkLocalUnicast() ||
+		address.IsLinkLocalMulticast() ||
+		address.IsInterfaceLocalMulticast() ||
+		address.IsMulticast()
+}
+
+func cleanWebhookEvents(events []string) ([]string, *SpotifeteError) {
+
+	var cleanedEvents []string
+	for _, event := range events {
+		if !isKnownWebhookEvent(event) {
+			return nil, NewUserError(fmt.Sprintf("Unknown webhook event %s. Possible events are %s.", event, strings.Join(webhookEvents, ", ")))
+		}
+
+		if !contains(cleanedEvents, event) {
+			cleanedEvents = append(cleanedEvents, event)
+		}
+	}
+
+	if len(cleanedEvents) == 0 {
+		return nil, NewUserError("A webhook needs at least one event.")
+	}
+
+	return cleanedEvents, nil
+}
+
+func isKnownWebhookEvent(event string) bool {
+	return contains(webhookEvents, event)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newWebhookSecret() (string, error) {
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// The delivery log of removed webhooks is kept
+func RemoveWebhook(session model.SimpleListeningSession, user model.SimpleUser, webhookId uint) *SpotifeteError {
+	if user.ID != session.OwnerId {
+		return NewUserError("Only the session owner can remove webhooks.")
+	}
+
+	webhook, spotifeteError := findWebhookOfSession(session, webhookId)
+	if spotifeteError != nil {
+		return spotifeteError
+	}
+
+	err := database.GetConnection().Delete(webhook).Error
+	if err != nil {
+		return NewInternalError("could not remove webhook", err)
+	}
+
+	return nil
+}
+
+func GetWebhookDeliveries(session model.SimpleListeningSession, user model.SimpleUser, webhookId uint, limit int) ([]model.WebhookDelivery, *SpotifeteError) {
+	if user.ID != session.OwnerId {
+		return nil, NewUserError("Only the session owner can see webhook deliveries.")
+	}
+	if limit < 1 || limit > MaxDeliveriesLimit {
+		return nil, NewUserError(fmt.Sprintf("Limit must be between 1 and %d.", MaxDeliveriesLimit))
+	}
+
+	webhook, spotifeteError := findWebhookOfSession(session, webhookId)
+	if spotifeteError != nil {
+		return nil, spotifeteError
+	}
+
+	var deliveries []model.WebhookDelivery
+	err := database.GetConnection().
+		Where(model.WebhookDelivery{WebhookId: webhook.ID}).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, NewInternalError("Could not fetch webhook deliveries from database", err)
+	}
+
+	return deliveries, nil
+}